@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"io"
+	"os"
+
+	"github.com/Abhaythakor/hyperwapp/diff"
+	"github.com/Abhaythakor/hyperwapp/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+	diffBy        string
+	diffChunkSize int
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff [old_results] [new_results]",
+	Short: "Compare two HyperWapp result files (jsonl, json or csv) and report what changed",
+	Args:  cobra.ExactArgs(2),
+	// Does not need the detection engine, so skip the root setup
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		setupLogging()
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		var out io.Writer = os.Stdout
+		colorize := !disableColor
+		if outputFile != "" {
+			file, err := os.Create(outputFile)
+			if err != nil {
+				util.Fatal("Failed to create output file: %v", err)
+			}
+			defer file.Close()
+			out = file
+			colorize = false
+		}
+
+		writer, err := diff.NewWriter(outputFormat, out, colorize)
+		if err != nil {
+			util.Fatal("%v", err)
+		}
+
+		summary, err := diff.Compare(args[0], args[1], diff.Options{By: diffBy, ChunkSize: diffChunkSize}, writer)
+		if err != nil {
+			util.Fatal("Diff failed: %v", err)
+		}
+		if outputFile != "" {
+			util.Info("%d change(s) written to %s", summary.Changes(), outputFile)
+		}
+	},
+}
+
+func init() {
+	diffCmd.Flags().StringVar(&diffBy, "by", diff.ByURL, "Compare targets by url or domain")
+	diffCmd.Flags().IntVar(&diffChunkSize, "chunk-size", diff.DefaultChunkSize, "Records sorted in memory before spilling to disk")
+	rootCmd.AddCommand(diffCmd)
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Abhaythakor/hyperwapp/detect"
+	"github.com/Abhaythakor/hyperwapp/util"
+	"github.com/spf13/cobra"
+)
+
+var fingerprintsCmd = &cobra.Command{
+	Use:   "fingerprints",
+	Short: "Manage custom fingerprint packs",
+	// Does not need the detection engine, so skip the root setup
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		setupLogging()
+	},
+}
+
+var fingerprintsLintCmd = &cobra.Command{
+	Use:   "lint [packs_dir]",
+	Short: "Check fingerprint packs for invalid patterns, duplicate names and broken implies",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		dir := fingerprintPacks
+		if len(args) > 0 {
+			dir = args[0]
+		}
+		if dir == "" {
+			util.Fatal("No packs directory provided. Pass it as an argument or use --fingerprint-packs.")
+		}
+
+		source, err := detect.ResolveFingerprints(fingerprintsPath)
+		if err != nil {
+			util.Fatal("Failed to load fingerprints: %v", err)
+		}
+		builtin, err := detect.LoadFingerprints(source)
+		if err != nil {
+			util.Fatal("Failed to load fingerprints: %v", err)
+		}
+
+		issues, err := detect.LintPacks(dir, builtin)
+		if err != nil {
+			util.Fatal("%v", err)
+		}
+
+		errors := 0
+		color := util.NewColorizer(!disableColor)
+		for _, issue := range issues {
+			if issue.Severity == "error" {
+				errors++
+				fmt.Println(color.Red(issue.String()))
+			} else {
+				fmt.Println(color.Yellow(issue.String()))
+			}
+		}
+
+		if errors > 0 {
+			util.Error("%d error(s), %d warning(s) in %s", errors, len(issues)-errors, dir)
+			os.Exit(1)
+		}
+		util.Info("%d warning(s), no errors in %s", len(issues), dir)
+	},
+}
+
+func init() {
+	fingerprintsCmd.AddCommand(fingerprintsLintCmd)
+	rootCmd.AddCommand(fingerprintsCmd)
+}
@@ -8,12 +8,17 @@ import (
 	"os/signal"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
+	"time"
 
 	"github.com/Abhaythakor/hyperwapp/config"
 	"github.com/Abhaythakor/hyperwapp/detect"
+	"github.com/Abhaythakor/hyperwapp/eol"
+	"github.com/Abhaythakor/hyperwapp/filter"
 	"github.com/Abhaythakor/hyperwapp/input"
 	"github.com/Abhaythakor/hyperwapp/input/custom"
 	"github.com/Abhaythakor/hyperwapp/input/online"
@@ -21,7 +26,10 @@ import (
 	"github.com/Abhaythakor/hyperwapp/model"
 	"github.com/Abhaythakor/hyperwapp/output"
 	"github.com/Abhaythakor/hyperwapp/progress"
+	"github.com/Abhaythakor/hyperwapp/ratelimit"
+	"github.com/Abhaythakor/hyperwapp/security"
 	"github.com/Abhaythakor/hyperwapp/util"
+	"github.com/Abhaythakor/hyperwapp/vuln"
 	"github.com/spf13/cobra"
 	"golang.org/x/term"
 )
@@ -42,6 +50,25 @@ var (
 	concurrency    int
 	cpus           int
 	timeout      int
+	maxBodySize  int64
+	requestHeaders  []string
+	cookies         []string
+	uaProfile       string
+	hostHeadersPath string
+	maxRedirects      int
+	sameHostRedirects bool
+	detectRedirects   bool
+	request         *online.Request
+	rateLimit        float64
+	rateLimitPerHost float64
+	retries          int
+	retryDelay       time.Duration
+	failedOutput     string
+	probePolicy      string
+	portSpecs        []string
+	probePorts       []int
+	excludeSpecs     []string
+	excluded         *input.AddressSet
 	forceColor   bool
 	disableColor bool
 	verbose      bool
@@ -50,12 +77,51 @@ var (
 	update       bool
 	showVersion  bool
 	showNuclei   bool // Added for nuclei bridge
-
-	wappalyzerEngine *detect.WappalyzerEngine
+	evidence     bool
+	securityHeaders bool
+	fingerprintsPath string
+	fingerprintPacks string
+	faviconDBPath    string
+	dnsResolver      string
+	bodyCacheSize    int
+	noBodyCache      bool
+	onlyVersioned bool
+	categories        []string
+	excludeCategories []string
+	minConfidence     int
+	matchTech         []string
+	excludeTech       []string
+	matchMode         string
+	resultFilter      *filter.Filter
+
+	engineNames      []string
+	engine           *detect.CompositeEngine
+	vulnDBPath       string
+	enricher         *vuln.Enricher
+	eolDBPath        string
+	onlyEOL          bool
+	eolDB            *eol.DB
 )
 
 var resumeMgr *util.ResumeManager
 
+// setupLogging applies the color and verbosity flags.
+func setupLogging() {
+	if forceColor {
+		util.SetColorEnabled(true)
+	} else if disableColor {
+		util.SetColorEnabled(false)
+	} else {
+		util.SetColorEnabled(util.NewColorizer(false).Enabled)
+	}
+
+	if verbose {
+		util.SetLogLevel(util.LevelDebug)
+	} else if silent {
+		util.SetLogLevel(util.LevelError)
+	}
+}
+
 var rootCmd = &cobra.Command{
 	Use:   "hyperwapp [flags] [input]",
 	Short: "HyperWapp is a CLI reconnaissance utility",
@@ -92,23 +158,11 @@ ADDITIONAL INFO:
   - Real-time JSONL output and checkpoint system for reliability.
 `,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-		if forceColor {
-			util.SetColorEnabled(true)
-		} else if disableColor {
-			util.SetColorEnabled(false)
-		} else {
-			util.SetColorEnabled(util.NewColorizer(false).Enabled)
-		}
-
-		if verbose {
-			util.SetLogLevel(util.LevelDebug)
-		} else if silent {
-			util.SetLogLevel(util.LevelError)
-		}
+		setupLogging()
 
 		if showVersion {
 			fmt.Printf("HyperWapp Version: %s\n", config.Version)
-			fmt.Printf("Wappalyzer Fingerprints: %s\n", detect.GetFingerprintsInfo())
+			fmt.Printf("Wappalyzer Fingerprints: %s\n", detect.GetFingerprintsInfo(fingerprintsPath))
 			os.Exit(0)
 		}
 
@@ -117,6 +171,12 @@ ADDITIONAL INFO:
 			if err := detect.UpdateFingerprints(); err != nil {
 				util.Warn("Failed to update fingerprints: %v", err)
 			}
+			if err := detect.UpdateFaviconDB(); err != nil {
+				util.Warn("Failed to update favicon database: %v", err)
+			}
+			if err := eol.Update(); err != nil {
+				util.Warn("Failed to update EOL dataset: %v", err)
+			}
 
 			// Update Binary via Go Install (Bypass cache with GOPROXY=direct)
 			util.Info("Updating HyperWapp binary via go install...")
@@ -160,12 +220,97 @@ ADDITIONAL INFO:
 			runtime.GOMAXPROCS(cpus)
 		}
 
+		if maxBodySize < 0 {
+			util.Fatal("--max-body-size must not be negative (use 0 for no limit)")
+		}
+
 		var err error
-		wappalyzerEngine, err = detect.NewWappalyzerEngine()
+		request, err = online.NewRequest(uaProfile, requestHeaders, cookies, hostHeadersPath)
+		if err != nil {
+			util.Fatal("Invalid request options: %v", err)
+		}
+
+		if rateLimit < 0 || rateLimitPerHost < 0 {
+			util.Fatal("--rate-limit and --rate-limit-per-host must not be negative (use 0 for no limit)")
+		}
+		if !slices.Contains(input.ProbePolicies(), probePolicy) {
+			util.Fatal("Invalid --probe policy %q (use %s)", probePolicy, strings.Join(input.ProbePolicies(), " or "))
+		}
+		probePorts, err = input.ParsePorts(portSpecs)
+		if err != nil {
+			util.Fatal("Invalid --ports: %v", err)
+		}
+		excluded, err = input.NewAddressSet(excludeSpecs)
+		if err != nil {
+			util.Fatal("Invalid --exclude: %v", err)
+		}
+
+		if maxRedirects < 0 {
+			util.Fatal("--max-redirects must not be negative (use 0 to not follow redirects)")
+		}
+		if retries < 0 || retryDelay < 0 {
+			util.Fatal("--retries and --retry-delay must not be negative")
+		}
+
+		if bodyCacheSize <= 0 && !noBodyCache {
+			util.Fatal("--body-cache-size must be positive (use --no-body-cache to disable the cache)")
+		}
+
+		resultFilter = &filter.Filter{
+			OnlyVersioned:     onlyVersioned,
+			Categories:        categories,
+			ExcludeCategories: excludeCategories,
+			MinConfidence:     minConfidence,
+			MatchTech:         matchTech,
+			ExcludeTech:       excludeTech,
+			MatchMode:         matchMode,
+			OnlyEOL:           onlyEOL,
+		}
+		if err := resultFilter.Compile(); err != nil {
+			util.Fatal("Invalid filter: %v", err)
+		}
+
+		engine, err = detect.NewEngine(engineNames, detect.EngineOptions{
+			FingerprintsPath: fingerprintsPath,
+			PacksDir:         fingerprintPacks,
+			BodyCacheSize:    bodyCacheSize,
+			NoBodyCache:      noBodyCache,
+			Evidence:         evidence,
+			FaviconDBPath:    faviconDBPath,
+			DNSResolver:      dnsResolver,
+		})
 		if err != nil {
-			util.Fatal("Failed to initialize Wappalyzer engine: %v", err)
+			util.Fatal("Failed to initialize detection engines: %v", err)
 		}
 
+		var (
+			cpeOf  func(string) string
+			vulnDB *vuln.DB
+		)
+		wappalyzerEngine, hasWappalyzer := engine.Lookup(detect.EngineWappalyzer).(*detect.WappalyzerEngine)
+		if hasWappalyzer {
+			cpeOf = wappalyzerEngine.CPE
+		}
+		if vulnDBPath != "" {
+			if !hasWappalyzer {
+				util.Fatal("--vuln-db needs the wappalyzer engine to map technologies to CPEs")
+			}
+			vulnDB, err = vuln.Load(vulnDBPath, wappalyzerEngine.CPEs())
+			if err != nil {
+				util.Fatal("Failed to load vulnerability database: %v", err)
+			}
+			util.Info("Loaded %d CVEs for %d products from %s", vulnDB.CVEs(), vulnDB.Products(), vulnDBPath)
+		}
+		enricher = vuln.NewEnricher(vulnDB, cpeOf)
+
+		var eolSource string
+		eolDB, eolSource, err = eol.Load(eolDBPath)
+		if err != nil {
+			util.Fatal("Failed to load EOL dataset: %v", err)
+		}
+		util.Debug("Using EOL dataset: %s", eolSource)
+		util.Debug("Detection engines: %s", strings.Join(engine.Names(), ", "))
+
 		resumeMgr, err = util.NewResumeManager(".HyperWapp.resume", resume)
 		if err != nil {
 			util.Warn("Failed to initialize resume manager: %v", err)
@@ -199,7 +344,7 @@ ADDITIONAL INFO:
 
 		var (
 			tracker  *progress.Tracker
-			resultCh <-chan []model.Detection
+			resultCh <-chan model.ScanResult
 		)
 
 		// Handle Ctrl+C for graceful shutdown and buffer flushing
@@ -208,13 +353,22 @@ ADDITIONAL INFO:
 
 		if proxyAddr != "" {
 			inputModeVal = "proxy"
-			tracker, resultCh = runProxy(ctx, proxyAddr, wappalyzerEngine)
+			tracker, resultCh = runProxy(ctx, proxyAddr, engine)
 		} else if offline {
 			inputModeVal = "offline"
-			tracker, resultCh = runOffline(ctx, inputSource, wappalyzerEngine)
+			tracker, resultCh = runOffline(ctx, inputSource, engine)
 		} else {
 			inputModeVal = "online"
-			tracker, resultCh = runOnline(ctx, inputSource, wappalyzerEngine)
+			tracker, resultCh = runOnline(ctx, inputSource, engine)
+		}
+
+		if wappalyzerEngine, ok := engine.Lookup(detect.EngineWappalyzer).(*detect.WappalyzerEngine); ok {
+			if _, ok := wappalyzerEngine.CacheStats(); ok {
+				tracker.AddSummary("Body Cache", func() string {
+					stats, _ := wappalyzerEngine.CacheStats()
+					return stats.String()
+				})
+			}
 		}
 
 		// Run result handler in the background
@@ -234,7 +388,7 @@ ADDITIONAL INFO:
 	},
 }
 
-func handleResults(resultCh <-chan []model.Detection, tracker *progress.Tracker, inputModeVal string) {
+func handleResults(resultCh <-chan model.ScanResult, tracker *progress.Tracker, inputModeVal string) {
 	cliWriter := output.NewCLIWriter(!disableColor)
 	if domain {
 		cliWriter.SetMode("domain")
@@ -259,11 +413,47 @@ func handleResults(resultCh <-chan []model.Detection, tracker *progress.Tracker,
 	var allNucleiTags []string
 	tagMap := make(map[string]struct{})
 
+	// Target metadata goes to the file only, once per target
+	writeTarget := func(info *model.TargetInfo) {
+		if info == nil || fileWriter == nil {
+			return
+		}
+		if err := fileWriter.WriteTarget(*info); err != nil {
+			util.Warn("Error writing to file: %v", err)
+		}
+	}
+
+	securitySummary := security.NewSummary()
+	writeSecurity := func(report *model.SecurityReport) {
+		if report == nil {
+			return
+		}
+		securitySummary.Record(report)
+		if !silent {
+			tracker.Clear()
+			if err := cliWriter.WriteSecurity(*report); err != nil {
+				util.Warn("Error writing to CLI: %v", err)
+			}
+		}
+		if fileWriter != nil {
+			if err := fileWriter.WriteSecurity(*report); err != nil {
+				util.Warn("Error writing to file: %v", err)
+			}
+		}
+	}
+
 	// High-speed result processing loop
-	for detections := range resultCh {
+	for result := range resultCh {
+		detections := result.Detections
 		if detections == nil {
+			writeTarget(result.Target)
+			writeSecurity(result.Security)
 			continue
 		}
+		enricher.Enrich(detections)
+		eolDB.Enrich(detections)
+		detections = resultFilter.Apply(detections)
+		enricher.Record(detections)
 
 		// Collect unique tags for final summary
 		for _, d := range detections {
@@ -288,9 +478,13 @@ func handleResults(resultCh <-chan []model.Detection, tracker *progress.Tracker,
 				util.Warn("Error writing to file: %v", err)
 			}
 		}
+		writeTarget(result.Target)
+		writeSecurity(result.Security)
 	}
 
 	tracker.Done()
+	enricher.WriteSummary(os.Stdout, util.NewColorizer(!disableColor))
+	securitySummary.WriteSummary(os.Stdout, util.NewColorizer(!disableColor))
 
 	// If --nuclei is set, print the bridge summary
 	if showNuclei && len(allNucleiTags) > 0 {
@@ -310,12 +504,12 @@ func isInputFromPipe() bool {
 	return !term.IsTerminal(int(os.Stdin.Fd()))
 }
 
-func runProxy(ctx context.Context, addr string, engine *detect.WappalyzerEngine) (*progress.Tracker, <-chan []model.Detection) {
+func runProxy(ctx context.Context, addr string, engine detect.Engine) (*progress.Tracker, <-chan model.ScanResult) {
 	tracker := progress.NewTracker(0, silent, !disableColor)
 	
 	// Create channels
 	proxyInputCh := make(chan model.OfflineInput, 100)
-	resultChWorker := make(chan []model.Detection, 100)
+	resultChWorker := make(chan model.ScanResult, 100)
 	
 	// Start Proxy Server
 	go func() {
@@ -368,7 +562,7 @@ func runProxy(ctx context.Context, addr string, engine *detect.WappalyzerEngine)
 							detections[i].NucleiTags = []string{tag}
 						}
 					}
-					resultChWorker <- detections
+					resultChWorker <- model.ScanResult{Detections: detections, Security: analyzeHeaders(input.Domain, input.URL, input.Headers)}
 					tracker.IncrementSuccess()
 				}
 			}
@@ -383,7 +577,7 @@ func runProxy(ctx context.Context, addr string, engine *detect.WappalyzerEngine)
 	return tracker, resultChWorker
 }
 
-func runOffline(ctx context.Context, inputSource string, engine *detect.WappalyzerEngine) (*progress.Tracker, <-chan []model.Detection) {
+func runOffline(ctx context.Context, inputSource string, engine *detect.CompositeEngine) (*progress.Tracker, <-chan model.ScanResult) {
 	absInputSource, err := filepath.Abs(inputSource)
 	if err != nil {
 		util.Fatal("Error resolving absolute path for input: %v", err)
@@ -418,7 +612,7 @@ func runOffline(ctx context.Context, inputSource string, engine *detect.Wappalyz
 	}
 
 	offlineWorkerInputCh := make(chan *model.OfflineInput, 2000) // Stable buffer for memory
-	resultChWorker := make(chan []model.Detection, 5000)      // Increased cushion for slow disks
+	resultChWorker := make(chan model.ScanResult, 5000)       // Increased cushion for slow disks
 	var wg sync.WaitGroup
 
 	numWorkers := concurrency
@@ -503,6 +697,7 @@ func runOffline(ctx context.Context, inputSource string, engine *detect.Wappalyz
 						model.OfflineInputPool.Put(offInput)
 						continue
 					}
+					detections = detect.MergeDetections(detections, engine.DetectTarget(model.Target{URL: offInput.URL, Domain: offInput.Domain}))
 
 					for i := range detections {
 						detections[i].Domain = offInput.Domain
@@ -513,7 +708,7 @@ func runOffline(ctx context.Context, inputSource string, engine *detect.Wappalyz
 						}
 					}
 
-					resultChWorker <- detections
+					resultChWorker <- model.ScanResult{Detections: detections, Security: analyzeHeaders(offInput.Domain, offInput.URL, offInput.Headers)}
 					resumeMgr.MarkCompleted(id)
 					tracker.IncrementSuccess()
 
@@ -539,15 +734,16 @@ func runOffline(ctx context.Context, inputSource string, engine *detect.Wappalyz
 	return tracker, resultChWorker
 }
 
-func runOnline(ctx context.Context, inputSource string, engine *detect.WappalyzerEngine) (*progress.Tracker, <-chan []model.Detection) {
-	targets, err := input.ResolveInput(inputSource, false)
+func runOnline(ctx context.Context, inputSource string, engine *detect.CompositeEngine) (*progress.Tracker, <-chan model.ScanResult) {
+	// Address ranges are expanded while targets are fed to the workers
+	source, err := input.ResolveSource(inputSource, input.ProbeOptions{Policy: probePolicy, Ports: probePorts}, excluded)
 	if err != nil {
 		util.Fatal("Error resolving input: %v", err)
 	}
 
-	tracker := progress.NewTracker(uint32(len(targets)), silent, !disableColor)
+	tracker := progress.NewTracker(source.Count(), silent, !disableColor)
 	targetCh := make(chan model.Target, 1000)
-	resultChWorker := make(chan []model.Detection, 2000)
+	resultChWorker := make(chan model.ScanResult, 2000)
 	var wg sync.WaitGroup
 
 	numWorkers := concurrency
@@ -555,6 +751,62 @@ func runOnline(ctx context.Context, inputSource string, engine *detect.Wappalyze
 		numWorkers = 1
 	}
 
+	// The detection strategy decides what is fetched: headers-only sweeps never
+	// download bodies.
+	sourceHint := model.SourceWappalyzer
+	if headersOnly {
+		sourceHint = model.SourceHeadersOnly
+	} else if bodyOnly {
+		sourceHint = model.SourceBodyOnly
+	}
+	fetchOpts := online.Options{HeadersOnly: headersOnly, MaxBodySize: maxBodySize, SkipBinary: true, Request: request, Retries: retries, RetryDelay: retryDelay}
+	fetchOpts.Redirects = &online.RedirectPolicy{Max: maxRedirects, SameHost: sameHostRedirects, KeepBodies: detectRedirects && !headersOnly}
+	// Follow-ups such as favicons obey the same redirect limits
+	followUpOpts := online.Options{MaxBodySize: maxBodySize, Request: request, Redirects: &online.RedirectPolicy{Max: maxRedirects, SameHost: sameHostRedirects}}
+	if excluded != nil {
+		// Also refuses hostnames and redirects resolving to an excluded address
+		fetchOpts.Exclude = excluded.Contains
+		followUpOpts.Exclude = excluded.Contains
+	}
+	followUpCache := detect.NewFollowUpCache(detect.DefaultFollowUpCacheSize)
+
+	// With rate limits, targets go through a scheduler that only releases a
+	// target once both the global and its host's limit allow it. Every request
+	// is then reserved with limiter.Wait, so the targets, their retries and
+	// follow-ups share one budget.
+	var workCh <-chan model.Target = targetCh
+	limiter := ratelimit.New(rateLimit, rateLimitPerHost)
+	if limiter != nil {
+		workCh = limiter.Schedule(ctx, targetCh)
+		tracker.SetRateInfo(func() string {
+			return fmt.Sprintf("%.1f req/s (limit %s)", limiter.EffectiveRate(), limiter)
+		})
+		tracker.AddSummary("Rate Limit", func() string {
+			return fmt.Sprintf("%d requests at %s", limiter.Sent(), limiter)
+		})
+	}
+	fetchOpts.Wait = limiter.Wait // Retries count against the limits too
+
+	// Targets that still fail after all retries are counted per class and can
+	// be written to a file to scan again later.
+	failures := &online.Failures{}
+	tracker.AddSummary("Failures", failures.String)
+	var probeLive, probeDead atomic.Uint32
+	tracker.AddSummary("Probe", func() string {
+		if probeLive.Load()+probeDead.Load() == 0 {
+			return ""
+		}
+		return fmt.Sprintf("%d live, %d not responding", probeLive.Load(), probeDead.Load())
+	})
+
+	var failedOut *util.LineWriter
+	if failedOutput != "" {
+		failedOut, err = util.NewLineWriter(failedOutput)
+		if err != nil {
+			util.Fatal("Failed to create failed targets file: %v", err)
+		}
+	}
+
 	for i := 0; i < numWorkers; i++ {
 		wg.Add(1)
 		go func() {
@@ -564,40 +816,138 @@ func runOnline(ctx context.Context, inputSource string, engine *detect.Wappalyze
 				select {
 				case <-ctx.Done():
 					return
-				case target, ok := <-targetCh:
+				case target, ok := <-workCh:
 					if !ok {
 						return
 					}
-					if resumeMgr.IsCompleted(target.URL) {
+					id := target.URL // Before a probe falls back to another URL
+					if resumeMgr.IsCompleted(id) {
 						tracker.IncrementSuccess()
 						continue
 					}
 					
-					headers, body, err := online.FetchOnline(ctx, target, timeout)
+					opts := fetchOpts
+					if target.Probe != "" {
+						opts.Retries = 0 // A probe that times out means "not live"
+						opts.OneShot = true
+					}
+					if err := limiter.Wait(ctx, target.URL); err != nil {
+						return // Interrupted while waiting for a slot
+					}
+					resp, err := online.FetchOnline(ctx, target, timeout, opts)
+					if err != nil && target.Fallback != "" && ctx.Err() == nil {
+						util.Debug("%s not live (%v), trying %s", target.URL, err, target.Fallback)
+						target.URL, target.Fallback = target.Fallback, ""
+						if err = limiter.Wait(ctx, target.URL); err == nil {
+							resp, err = online.FetchOnline(ctx, target, timeout, opts)
+						}
+					}
 					if err != nil {
-						util.Warn("Failed: %s (%v)", target.URL, err)
+						if ctx.Err() != nil {
+							return // Interrupted, not a failure of the target
+						}
+						if target.Probe != "" {
+							// Probed schemes that never answered are not targets
+							util.Debug("Not live: %s (%v)", target.URL, err)
+							probeDead.Add(1)
+							resumeMgr.MarkCompleted(id)
+							tracker.Increment()
+							continue
+						}
+						class := failures.Record(err)
+						util.Warn("Failed: %s [%s] (%v)", target.URL, class, err)
+						failedOut.WriteLine(target.URL)
 						tracker.IncrementError()
 						continue
 					}
+					if resp.Failure != nil {
+						// Still scanned: challenge and maintenance pages have headers worth detecting
+						class := failures.Record(resp.Failure)
+						util.Warn("Still failing after retries: %s [%s]", target.URL, class)
+						failedOut.WriteLine(target.URL)
+					}
+					target.TLS = resp.TargetTLS() // Not the certificate of another host redirected to
 
-					detections, err := engine.Detect(headers, body, model.SourceWappalyzer)
+					detections, err := engine.Detect(resp.Headers, resp.Body, sourceHint)
 					if err != nil {
 						util.Warn("Failed to detect for %s: %v", target.URL, err)
 						tracker.IncrementError()
 						continue
 					}
+					markTruncated(detections, resp.Truncated)
+
+					// Technologies of the redirect hops, such as an SSO gateway or
+					// load balancer in front of the final page
+					if detectRedirects {
+						for _, hop := range resp.Redirects {
+							hopDetections, err := engine.Detect(hop.Headers, hop.Body, sourceHint)
+							if err != nil {
+								util.Debug("Failed to detect for %s: %v", hop.URL, err)
+								continue
+							}
+							markTruncated(hopDetections, hop.Truncated)
+							for i := range hopDetections {
+								hopDetections[i].HopURL = hop.URL
+							}
+							detections = detect.MergeDetections(detections, hopDetections)
+						}
+					}
+					detections = detect.MergeDetections(detections, engine.DetectTarget(target))
 
+					// Extra resources requested by engines (e.g. favicons)
+					var followUps []detect.FollowUp
+					if !headersOnly {
+						followUps = engine.FollowUps(resp.URL, resp.Body) // Relative links resolve against the final page
+					}
+					for _, followUp := range followUps {
+						if ctx.Err() != nil {
+							break
+						}
+						// Resources shared by several targets are fetched once per scan
+						extra := followUpCache.Detect(followUp, func() []model.Detection {
+							if err := limiter.Wait(ctx, followUp.URL); err != nil {
+								return nil
+							}
+							fResp, err := online.FetchOnline(ctx, model.Target{URL: followUp.URL, Domain: target.Domain}, timeout, followUpOpts)
+							if err != nil {
+								util.Debug("Failed to fetch %s: %v", followUp.URL, err)
+								return nil
+							}
+							if fResp.Truncated {
+								util.Debug("Skipping %s: body larger than --max-body-size", followUp.URL)
+								return nil
+							}
+							extra, err := followUp.Engine.Detect(fResp.Headers, fResp.Body, model.SourceWappalyzer)
+							if err != nil {
+								util.Debug("Failed to detect for %s: %v", followUp.URL, err)
+								return nil
+							}
+							return extra
+						})
+						detections = detect.MergeDetections(detections, extra)
+					}
+
+					redirects := resp.Chain()
 					for i := range detections {
 						detections[i].Domain = target.Domain
 						detections[i].URL = target.URL
+						detections[i].FinalURL = resp.URL
+						detections[i].Redirects = redirects
 						// Parallel mapping
 						if tag := detect.MapToNucleiTag(detections[i].Technology); tag != "" {
 							detections[i].NucleiTags = []string{tag}
 						}
 					}
 
-					resultChWorker <- detections
-					resumeMgr.MarkCompleted(target.URL)
+					var info *model.TargetInfo
+					if target.TLS != nil {
+						info = &model.TargetInfo{Type: model.RecordTarget, Domain: target.Domain, URL: target.URL, TLS: target.TLS, Timestamp: time.Now().UTC()}
+					}
+					resultChWorker <- model.ScanResult{Detections: detections, Target: info, Security: analyzeHeaders(target.Domain, resp.URL, resp.Headers)} // Graded against the scheme of the final page
+					if target.Probe != "" {
+						probeLive.Add(1)
+					}
+					resumeMgr.MarkCompleted(id)
 					tracker.IncrementSuccess()
 				}
 			}
@@ -605,25 +955,48 @@ func runOnline(ctx context.Context, inputSource string, engine *detect.Wappalyze
 	}
 
 	go func() {
-		for _, target := range targets {
+		source.Each(func(target model.Target) bool {
 			select {
 			case <-ctx.Done():
-				close(targetCh)
-				return
+				return false
 			case targetCh <- target:
+				return true
 			}
-		}
+		})
 		close(targetCh)
 	}()
 
 	go func() {
 		wg.Wait()
+		if err := failedOut.Close(); err != nil {
+			util.Warn("Failed to write failed targets file: %v", err)
+		}
 		close(resultChWorker)
 	}()
 
 	return tracker, resultChWorker
 }
 
+// markTruncated flags body-stage detections made on a body cut at --max-body-size.
+func markTruncated(detections []model.Detection, truncated bool) {
+	if !truncated {
+		return
+	}
+	for i := range detections {
+		if detections[i].Stage == detect.StageBody || detections[i].Stage == detect.StageBoth {
+			detections[i].Truncated = true
+		}
+	}
+}
+
+// analyzeHeaders grades the response headers of a target when --security-headers is set.
+func analyzeHeaders(domain, targetURL string, headers map[string][]string) *model.SecurityReport {
+	if !securityHeaders {
+		return nil
+	}
+	return security.Analyze(domain, targetURL, headers)
+}
+
 func setupWriter(outputFormat, outputFile string, colorize bool, inputType string, version string, resume bool) (output.Writer, error) {
 	switch outputFormat {
 	case "csv":
@@ -650,8 +1023,11 @@ func Execute() {
 
 func init() {
 	// Input Group
-	rootCmd.PersistentFlags().StringVarP(&url, "url", "u", "", "Single URL to scan")
-	rootCmd.PersistentFlags().StringVarP(&urlList, "list", "l", "", "File containing list of URLs to scan")
+	rootCmd.PersistentFlags().StringVarP(&url, "url", "u", "", "Single URL, host, host:port, IP, CIDR or IP range to scan")
+	rootCmd.PersistentFlags().StringVarP(&urlList, "list", "l", "", "File containing list of URLs, hosts, host:port, IPs, CIDRs or IP ranges to scan")
+	rootCmd.PersistentFlags().StringVar(&probePolicy, "probe", input.ProbeHTTPS, "Schemes probed for inputs without one (bare hosts, host:port, IPs): https (then http if https is not live) or both")
+	rootCmd.PersistentFlags().StringSliceVar(&portSpecs, "ports", nil, "Ports probed for bare hosts and IPs without a port, e.g. 80,443,8000-8010 (default: 443 for https, 80 for http)")
+	rootCmd.PersistentFlags().StringSliceVar(&excludeSpecs, "exclude", nil, "IPs, CIDRs or ranges never connected to, e.g. 10.0.0.1,10.0.5.0/24 or a file with one per line")
 	rootCmd.PersistentFlags().StringVar(&proxyAddr, "proxy", "", "Start a proxy server on this address (e.g., :8080) to passively scan traffic")
 	rootCmd.PersistentFlags().StringVar(&inputConfigPath, "input-config", "", "YAML file defining custom input parsing (JSON or Regex support)")
 	rootCmd.PersistentFlags().BoolVar(&offline, "offline", false, "Offline mode: recursively parse directory structure (Katana, FFF, etc.)")
@@ -660,20 +1036,56 @@ func init() {
 	rootCmd.PersistentFlags().BoolVar(&headersOnly, "headers-only", false, "Detect technologies using HTTP headers only")
 	rootCmd.PersistentFlags().BoolVar(&bodyOnly, "body-only", false, "Detect technologies using HTTP body only")
 	rootCmd.PersistentFlags().BoolVar(&auto, "auto", true, "Detect using both headers and body (default)")
+	rootCmd.PersistentFlags().StringVar(&fingerprintsPath, "fingerprints", "", "Wappalyzer fingerprints JSON file (default: file downloaded by --update, then embedded data)")
+	rootCmd.PersistentFlags().StringSliceVar(&engineNames, "engines", []string{detect.EngineWappalyzer}, "Comma-separated detection engines to run ("+strings.Join(detect.EngineNames(), ", ")+")")
+	rootCmd.PersistentFlags().StringVar(&faviconDBPath, "favicon-db", "", "Favicon hash database JSON file for the favicon engine (default: file downloaded by --update, then embedded data)")
+	rootCmd.PersistentFlags().StringVar(&dnsResolver, "resolver", "", "DNS resolver (ip or ip:port) for the dns engine (default: first nameserver in /etc/resolv.conf)")
+	rootCmd.PersistentFlags().StringVar(&vulnDBPath, "vuln-db", "", "Directory of NVD JSON feeds (*.json, *.json.gz) used to attach CVEs to versioned detections (offline)")
+	rootCmd.PersistentFlags().StringVar(&eolDBPath, "eol-db", "", "End-of-life dataset JSON file (default: file downloaded by --update, then embedded data)")
+	rootCmd.PersistentFlags().StringVar(&fingerprintPacks, "fingerprint-packs", "", "Directory of custom fingerprint packs (Wappalyzer JSON or YAML) merged with the built-in fingerprints")
+	rootCmd.PersistentFlags().BoolVar(&evidence, "evidence", false, "Record where each technology matched (header, cookie, meta, script src or HTML) and the matched text")
+	rootCmd.PersistentFlags().BoolVar(&detectRedirects, "detect-redirects", false, "Also detect technologies on every redirect hop of online targets (reported with hop_url)")
+	rootCmd.PersistentFlags().BoolVar(&securityHeaders, "security-headers", false, "Grade the security headers of every response (CSP, HSTS, X-Frame-Options, cookie flags, Server/X-Powered-By disclosure)")
+
+	// Request Group
+	rootCmd.PersistentFlags().StringArrayVarP(&requestHeaders, "header", "H", nil, "Custom header sent with every online request, as \"Name: value\" (repeatable)")
+	rootCmd.PersistentFlags().StringArrayVar(&cookies, "cookie", nil, "Cookies sent with every online request, as \"name=value; other=value\" (repeatable)")
+	rootCmd.PersistentFlags().StringVar(&uaProfile, "ua", online.ProfileDefault, "User-Agent profile for online requests ("+strings.Join(online.ProfileNames(), ", ")+"); random rotates browser profiles per request")
+	rootCmd.PersistentFlags().StringVar(&hostHeadersPath, "host-headers", "", "YAML file of per-host headers overriding -H/--cookie/--ua (keys: host, host:port or *.domain)")
+	rootCmd.PersistentFlags().IntVar(&maxRedirects, "max-redirects", online.DefaultMaxRedirects, "Maximum redirects followed per online target; the last response is scanned when the limit is hit (0 = do not follow)")
+	rootCmd.PersistentFlags().BoolVar(&sameHostRedirects, "same-host-redirects", false, "Only follow redirects to the same hostname; a redirect elsewhere is scanned as the final response")
 
 	// Output Mode Group
 	rootCmd.PersistentFlags().BoolVar(&all, "all", false, "Output results per URL (default)")
 	rootCmd.PersistentFlags().BoolVar(&domain, "domain", false, "Aggregate and output results per unique domain")
 
+	// Filter Group
+	rootCmd.PersistentFlags().BoolVar(&onlyVersioned, "only-versioned", false, "Only output technologies with a detected version")
+	rootCmd.PersistentFlags().StringSliceVar(&categories, "category", nil, "Only output technologies in these Wappalyzer categories (e.g., CMS,CDN)")
+	rootCmd.PersistentFlags().StringSliceVar(&excludeCategories, "exclude-category", nil, "Do not output technologies in these Wappalyzer categories (e.g., Analytics)")
+	rootCmd.PersistentFlags().IntVar(&minConfidence, "min-confidence", 0, "Only output technologies with a confidence score of at least this value (0-100)")
+	rootCmd.PersistentFlags().BoolVar(&onlyEOL, "only-eol", false, "Only output technologies whose detected version is end-of-life")
+	rootCmd.PersistentFlags().StringSliceVar(&matchTech, "match-tech", nil, "Only output these technologies (exact name, glob like 'Word*', or regex like '/^jquery/')")
+	rootCmd.PersistentFlags().StringSliceVar(&excludeTech, "exclude-tech", nil, "Do not output these technologies (exact name, glob or regex)")
+	rootCmd.PersistentFlags().StringVar(&matchMode, "match-mode", filter.MatchModeTech, "How --match-tech/--exclude-tech apply: tech (drop non-matching technologies) or target (keep whole targets that match)")
+
 	// Export Group
 	rootCmd.PersistentFlags().StringVarP(&outputFile, "output", "o", "", "Write output to specified file")
 	rootCmd.PersistentFlags().StringVarP(&outputFormat, "format", "f", "cli", "Output format: csv, json, jsonl, txt, md")
+	rootCmd.PersistentFlags().StringVar(&failedOutput, "failed-output", "", "Write online targets that failed after all retries to this file, one URL per line (feed it back with -l)")
 
 	// Performance Group
 	rootCmd.PersistentFlags().IntVarP(&concurrency, "concurrency", "c", runtime.NumCPU()*2, "Number of concurrent workers (goroutines)")
 	rootCmd.PersistentFlags().IntVarP(&concurrency, "threads", "t", runtime.NumCPU()*2, "Number of concurrent workers (alias for --concurrency)")
 	rootCmd.PersistentFlags().IntVar(&cpus, "cpus", 0, "Limit number of physical CPU cores to use (GOMAXPROCS)")
 	rootCmd.PersistentFlags().IntVar(&timeout, "timeout", 10, "HTTP timeout in seconds for online scanning")
+	rootCmd.PersistentFlags().Float64Var(&rateLimit, "rate-limit", 0, "Maximum online requests per second across all workers (0 = unlimited)")
+	rootCmd.PersistentFlags().Float64Var(&rateLimitPerHost, "rate-limit-per-host", 0, "Maximum online requests per second to a single host; other hosts keep being scanned meanwhile (0 = unlimited)")
+	rootCmd.PersistentFlags().IntVar(&retries, "retries", 2, "Retries per online target after a timeout, connection reset or 429/502/503 response (0 = no retries)")
+	rootCmd.PersistentFlags().DurationVar(&retryDelay, "retry-delay", online.DefaultRetryDelay, "Base delay before a retry, doubled on each attempt with jitter; a Retry-After header takes precedence")
+	rootCmd.PersistentFlags().Int64Var(&maxBodySize, "max-body-size", online.DefaultMaxBodySize, "Maximum response body size in bytes read per online target; longer bodies are truncated (0 = no limit)")
+	rootCmd.PersistentFlags().IntVar(&bodyCacheSize, "body-cache-size", detect.DefaultBodyCacheSize, "Maximum number of body scan results kept in the LRU cache")
+	rootCmd.PersistentFlags().BoolVar(&noBodyCache, "no-body-cache", false, "Disable the body scan cache")
 
 	// UI & Debug Group
 	rootCmd.PersistentFlags().BoolVar(&forceColor, "color", false, "Force colored CLI output")
@@ -0,0 +1,153 @@
+package detect
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultBodyCacheSize is the default number of body scan results kept in memory.
+const DefaultBodyCacheSize = 10000
+
+// CacheStats holds the counters of a BodyCache.
+type CacheStats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	Entries   int
+	Capacity  int
+}
+
+// String renders the counters for the final scan summary.
+func (s CacheStats) String() string {
+	rate := 0.0
+	if lookups := s.Hits + s.Misses; lookups > 0 {
+		rate = float64(s.Hits) / float64(lookups) * 100
+	}
+	return fmt.Sprintf("hits %d, misses %d, evictions %d (%.1f%% hit rate, %d/%d entries)",
+		s.Hits, s.Misses, s.Evictions, rate, s.Entries, s.Capacity)
+}
+
+// lru is a fixed-size least recently used map, safe for concurrent use.
+type lru[K comparable, V any] struct {
+	mu        sync.Mutex
+	capacity  int
+	order     *list.List // Front is most recently used
+	entries   map[K]*list.Element
+	evictions atomic.Uint64
+}
+
+type lruEntry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+func newLRU[K comparable, V any](capacity int) *lru[K, V] {
+	return &lru[K, V]{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[K]*list.Element, capacity),
+	}
+}
+
+// get returns the value of key and marks it as recently used.
+func (c *lru[K, V]) get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*lruEntry[K, V]).value, true
+}
+
+// add stores value under key unless the key is already present, evicting the
+// least recently used entry if the cache is full. It returns the value now
+// cached, which is the existing one if another caller added it first.
+func (c *lru[K, V]) add(key K, value V) V {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		c.order.MoveToFront(elem)
+		return elem.Value.(*lruEntry[K, V]).value
+	}
+
+	c.entries[key] = c.order.PushFront(&lruEntry[K, V]{key: key, value: value})
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry[K, V]).key)
+		c.evictions.Add(1)
+	}
+	return value
+}
+
+// remove drops key from the cache, if present.
+func (c *lru[K, V]) remove(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+	}
+}
+
+// len returns the number of cached entries.
+func (c *lru[K, V]) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// BodyCache is a fixed-size LRU cache of body scan results keyed by the SHA-256
+// of the pruned body. Identical pages (error pages, parked domains, CDN
+// templates) are common in large runs, so the most recently seen ones are kept.
+type BodyCache struct {
+	entries *lru[[32]byte, map[string]struct{}]
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+// NewBodyCache creates a cache holding at most capacity entries.
+func NewBodyCache(capacity int) *BodyCache {
+	if capacity <= 0 {
+		capacity = DefaultBodyCacheSize
+	}
+	return &BodyCache{entries: newLRU[[32]byte, map[string]struct{}](capacity)}
+}
+
+// Get returns the cached fingerprints of a body and marks them as recently used.
+func (c *BodyCache) Get(key [32]byte) (map[string]struct{}, bool) {
+	fingerprints, ok := c.entries.get(key)
+	if !ok {
+		c.misses.Add(1)
+		return nil, false
+	}
+	c.hits.Add(1)
+	return fingerprints, true
+}
+
+// Put stores the fingerprints of a body, evicting the least recently used
+// entry if the cache is full. If another worker scanned the same body
+// concurrently, the first result is kept.
+func (c *BodyCache) Put(key [32]byte, fingerprints map[string]struct{}) {
+	c.entries.add(key, fingerprints)
+}
+
+// Stats returns a snapshot of the cache counters.
+func (c *BodyCache) Stats() CacheStats {
+	return CacheStats{
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Evictions: c.entries.evictions.Load(),
+		Entries:   c.entries.len(),
+		Capacity:  c.entries.capacity,
+	}
+}
@@ -0,0 +1,73 @@
+package detect
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func cacheKey(i int) [32]byte {
+	return sha256.Sum256([]byte(fmt.Sprintf("body-%d", i)))
+}
+
+func TestBodyCacheEviction(t *testing.T) {
+	cache := NewBodyCache(2)
+	cache.Put(cacheKey(1), map[string]struct{}{"Nginx": {}})
+	cache.Put(cacheKey(2), map[string]struct{}{"PHP": {}})
+
+	// Touch 1 so that 2 becomes the least recently used entry
+	if _, ok := cache.Get(cacheKey(1)); !ok {
+		t.Fatal("entry 1 should be cached")
+	}
+	cache.Put(cacheKey(3), map[string]struct{}{"jQuery": {}})
+
+	if _, ok := cache.Get(cacheKey(2)); ok {
+		t.Error("entry 2 should have been evicted")
+	}
+	if fingerprints, ok := cache.Get(cacheKey(1)); !ok || len(fingerprints) != 1 {
+		t.Error("entry 1 should still be cached")
+	}
+	if _, ok := cache.Get(cacheKey(3)); !ok {
+		t.Error("entry 3 should be cached")
+	}
+
+	stats := cache.Stats()
+	want := CacheStats{Hits: 3, Misses: 1, Evictions: 1, Entries: 2, Capacity: 2}
+	if stats != want {
+		t.Errorf("Stats() = %+v, want %+v", stats, want)
+	}
+	if !strings.Contains(stats.String(), "75.0% hit rate") {
+		t.Errorf("String() = %q, want a 75.0%% hit rate", stats.String())
+	}
+}
+
+func TestEngineBodyCache(t *testing.T) {
+	body := []byte(`<html><head><meta name="generator" content="WordPress 6.4.2"></head><body>` + strings.Repeat("<p>filler</p>", 200) + `</body></html>`)
+
+	engine, err := NewWappalyzerEngine(EngineOptions{BodyCacheSize: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := engine.Detect(nil, body, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stats, ok := engine.CacheStats()
+	if !ok || stats.Hits != 2 || stats.Misses != 1 {
+		t.Errorf("CacheStats() = %+v, %v, want 2 hits and 1 miss", stats, ok)
+	}
+
+	disabled, err := NewWappalyzerEngine(EngineOptions{NoBodyCache: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	detections, err := disabled.Detect(nil, body, "")
+	if err != nil || len(detections) == 0 {
+		t.Errorf("Detect() without cache = %v, %v", detections, err)
+	}
+	if _, ok := disabled.CacheStats(); ok {
+		t.Error("CacheStats() should report a disabled cache")
+	}
+}
@@ -0,0 +1,101 @@
+package detect
+
+import (
+	"github.com/Abhaythakor/hyperwapp/model"
+)
+
+// stageAgreementBonus is added to the confidence of technologies found by both
+// the header and the body stage.
+const stageAgreementBonus = 10
+
+// annotate computes the confidence score of each detection and, in evidence mode,
+// records where it matched. Pattern matching is only needed for technologies with
+// partial-confidence patterns unless evidence is requested, so the default path
+// stays cheap for the vast majority of fingerprints.
+func (e *WappalyzerEngine) annotate(detections []model.Detection, headers map[string][]string, scanBody []byte) {
+	names := make([]string, len(detections))
+	for i := range detections {
+		names[i] = detections[i].Technology
+	}
+
+	implied := make([]int, 0)
+	for i := range detections {
+		d := &detections[i]
+		patterns := e.appPatterns(d.Technology)
+		if patterns == nil {
+			// No fingerprint of its own: only reachable through "implies"
+			implied = append(implied, i)
+			continue
+		}
+		if !e.evidence && !patterns.partial {
+			d.ConfidenceScore = scoreWithBonus(100, d.Stage)
+			continue
+		}
+
+		var matches []patternMatch
+		if d.Stage != StageBody {
+			matches = append(matches, e.matchHeaders(d.Technology, headers)...)
+		}
+		if d.Stage != StageHeader {
+			matches = append(matches, e.matchBody(d.Technology, scanBody)...)
+		}
+		if len(matches) == 0 {
+			implied = append(implied, i)
+			continue
+		}
+
+		if e.evidence {
+			d.Path = matches[0].location()
+			d.Evidence = truncateEvidence(matches[0].matched)
+		}
+
+		// Same rule as Wappalyzer: pattern confidences add up, capped at 100
+		score := 0
+		for _, m := range matches {
+			score += m.confidence
+		}
+		d.ConfidenceScore = scoreWithBonus(score, d.Stage)
+	}
+
+	// Implied technologies inherit the confidence of the technology implying them,
+	// reduced by the confidence modifier of the implication.
+	for _, i := range implied {
+		d := &detections[i]
+		implier, modifier := e.impliedBy(d.Technology, names)
+		if implier == "" {
+			// Matched by a pattern type we do not re-evaluate (e.g. js, dom)
+			d.ConfidenceScore = scoreWithBonus(100, d.Stage)
+			continue
+		}
+
+		score := modifier
+		for j := range detections {
+			if detections[j].Technology == implier && detections[j].ConfidenceScore > 0 && detections[j].ConfidenceScore < score {
+				score = detections[j].ConfidenceScore
+			}
+		}
+		d.ConfidenceScore = scoreWithBonus(score, d.Stage)
+		if e.evidence {
+			d.Path = PartImplied
+			d.Evidence = implier
+		}
+	}
+
+	for i := range detections {
+		detections[i].Confidence = model.ConfidenceBucket(detections[i].ConfidenceScore)
+	}
+}
+
+// scoreWithBonus applies the stage agreement bonus and clamps the score to 0-100.
+func scoreWithBonus(score int, stage string) int {
+	if stage == StageBoth {
+		score += stageAgreementBonus
+	}
+	if score > 100 {
+		return 100
+	}
+	if score < 0 {
+		return 0
+	}
+	return score
+}
@@ -0,0 +1,329 @@
+package detect
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Abhaythakor/hyperwapp/model"
+	"github.com/Abhaythakor/hyperwapp/util"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// EngineDNS is the registry name of the DNS CNAME engine.
+const EngineDNS = "dns"
+
+const (
+	dnsConfidence     = 90
+	maxCNAMEHops      = 8
+	defaultDNSTimeout = 5 * time.Second
+	dnsCacheSize      = 100000 // Domains whose CNAME chain is kept in memory
+)
+
+// cnameRule maps a CNAME target suffix to a technology.
+type cnameRule struct {
+	suffix     string
+	technology string
+	categories []string
+}
+
+// cnameRules identifies hosting platforms and CDNs by the names targets are
+// aliased to. Names match the Wappalyzer technologies where one exists.
+var cnameRules = []cnameRule{
+	{"cloudfront.net", "Amazon CloudFront", []string{"CDN"}},
+	{"elb.amazonaws.com", "Amazon ELB", []string{"Load balancers"}},
+	{"s3.amazonaws.com", "Amazon S3", []string{"CDN"}},
+	{"s3-website.amazonaws.com", "Amazon S3", []string{"CDN"}},
+	{"awsglobalaccelerator.com", "Amazon Web Services", []string{"PaaS"}},
+	{"elasticbeanstalk.com", "Amazon Web Services", []string{"PaaS"}},
+	{"azurewebsites.net", "Azure", []string{"PaaS"}},
+	{"cloudapp.net", "Azure", []string{"PaaS"}},
+	{"cloudapp.azure.com", "Azure", []string{"PaaS"}},
+	{"trafficmanager.net", "Azure", []string{"PaaS"}},
+	{"azureedge.net", "Azure CDN", []string{"CDN"}},
+	{"azurefd.net", "Azure Front Door", []string{"Load balancers"}},
+	{"herokudns.com", "Heroku", []string{"PaaS"}},
+	{"herokuapp.com", "Heroku", []string{"PaaS"}},
+	{"herokussl.com", "Heroku", []string{"PaaS"}},
+	{"myshopify.com", "Shopify", []string{"Ecommerce"}},
+	{"cdn.cloudflare.net", "Cloudflare", []string{"CDN"}},
+	{"edgekey.net", "Akamai", []string{"CDN"}},
+	{"edgesuite.net", "Akamai", []string{"CDN"}},
+	{"akamaiedge.net", "Akamai", []string{"CDN"}},
+	{"akamai.net", "Akamai", []string{"CDN"}},
+	{"akamaized.net", "Akamai", []string{"CDN"}},
+	{"fastly.net", "Fastly", []string{"CDN"}},
+	{"fastlylb.net", "Fastly", []string{"CDN"}},
+	{"incapdns.net", "Imperva", []string{"Security", "CDN"}},
+	{"sucuri.net", "Sucuri", []string{"CDN", "Security"}},
+	{"stackpathdns.com", "StackPath", []string{"CDN"}},
+	{"b-cdn.net", "Bunny", []string{"CDN"}},
+	{"github.io", "GitHub Pages", []string{"PaaS"}},
+	{"netlify.app", "Netlify", []string{"PaaS", "CDN"}},
+	{"netlify.com", "Netlify", []string{"PaaS", "CDN"}},
+	{"vercel-dns.com", "Vercel", []string{"PaaS"}},
+	{"vercel.app", "Vercel", []string{"PaaS"}},
+	{"fly.dev", "Fly.io", []string{"PaaS"}},
+	{"onrender.com", "Render", []string{"PaaS"}},
+	{"appspot.com", "Google App Engine", []string{"Web servers"}},
+	{"ghs.googlehosted.com", "Google Sites", []string{"CMS"}},
+	{"firebaseapp.com", "Firebase", []string{"Databases", "Development"}},
+	{"web.app", "Firebase", []string{"Databases", "Development"}},
+	{"wpengine.com", "WP Engine", []string{"PaaS", "Hosting"}},
+	{"kinsta.cloud", "Kinsta", []string{"PaaS", "Hosting"}},
+	{"pantheonsite.io", "Pantheon", []string{"PaaS"}},
+	{"acquia-sites.com", "Acquia Cloud Platform", []string{"PaaS"}},
+	{"wordpress.com", "WordPress.com", []string{"PaaS"}},
+	{"squarespace.com", "Squarespace", []string{"CMS"}},
+	{"wixdns.net", "Wix", []string{"CMS", "Blogs"}},
+	{"webflow.io", "Webflow", []string{"Page builders", "CMS"}},
+	{"ghost.io", "Ghost", []string{"CMS", "Blogs"}},
+	{"zendesk.com", "Zendesk", []string{"Issue trackers", "Live chat"}},
+	{"hubspot.net", "HubSpot", []string{"Marketing automation"}},
+	{"unbouncepages.com", "Unbounce", []string{"Page builders"}},
+	{"tumblr.com", "Tumblr", []string{"Blogs"}},
+}
+
+// dnsEntry is the cached CNAME result of a domain. once makes concurrent
+// targets on the same domain share a single lookup.
+type dnsEntry struct {
+	once  sync.Once
+	chain []string
+	err   error
+}
+
+// DNSEngine resolves the CNAME chain of each target domain and matches it
+// against cnameRules. The chains of the most recently seen domains are cached,
+// so targets sharing a domain are resolved once.
+type DNSEngine struct {
+	server   string // host:port; empty to use the system resolver (canonical name only)
+	timeout  time.Duration
+	evidence bool
+	cache    *lru[string, *dnsEntry]
+}
+
+func init() {
+	RegisterEngine(EngineDNS, func(opts EngineOptions) (Engine, error) {
+		return NewDNSEngine(opts)
+	})
+}
+
+// NewDNSEngine creates the DNS engine. opts.DNSResolver selects the resolver
+// ("1.1.1.1" or "1.1.1.1:53"); by default the first nameserver of
+// /etc/resolv.conf is used.
+func NewDNSEngine(opts EngineOptions) (*DNSEngine, error) {
+	server := opts.DNSResolver
+	if server == "" {
+		server = systemNameserver()
+	}
+	if server != "" {
+		if _, _, err := net.SplitHostPort(server); err != nil {
+			server = net.JoinHostPort(server, "53")
+		}
+		util.Debug("Using DNS resolver %s", server)
+	} else {
+		util.Debug("No nameserver found, using the system resolver (canonical names only)")
+	}
+
+	timeout := opts.DNSTimeout
+	if timeout <= 0 {
+		timeout = defaultDNSTimeout
+	}
+	return &DNSEngine{
+		server:   server,
+		timeout:  timeout,
+		evidence: opts.Evidence,
+		cache:    newLRU[string, *dnsEntry](dnsCacheSize),
+	}, nil
+}
+
+// systemNameserver returns the first nameserver in /etc/resolv.conf, if any.
+func systemNameserver() string {
+	file, err := os.Open("/etc/resolv.conf")
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "nameserver" {
+			return fields[1]
+		}
+	}
+	return ""
+}
+
+// Detect does nothing: the DNS engine only looks at the target domain.
+func (e *DNSEngine) Detect(headers map[string][]string, body []byte, sourceHint string) ([]model.Detection, error) {
+	return nil, nil
+}
+
+// DetectTarget matches the CNAME chain of the target domain against cnameRules.
+func (e *DNSEngine) DetectTarget(target model.Target) ([]model.Detection, error) {
+	domain := dnsName(target.Domain)
+	if domain == "" {
+		return nil, nil
+	}
+
+	// A failing hop still leaves the names resolved before it to match
+	chain, err := e.chain(domain)
+
+	now := time.Now().UTC()
+	seen := make(map[string]struct{})
+	var detections []model.Detection
+	for _, name := range chain {
+		for _, rule := range cnameRules {
+			if _, ok := seen[rule.technology]; ok || !hasDomainSuffix(name, rule.suffix) {
+				continue
+			}
+			seen[rule.technology] = struct{}{}
+
+			d := model.Detection{
+				Technology:      rule.technology,
+				Categories:      rule.categories,
+				Source:          model.SourceDNS,
+				Stage:           EngineDNS,
+				Path:            "fingerprint",
+				Evidence:        EngineDNS,
+				ConfidenceScore: dnsConfidence,
+				Confidence:      model.ConfidenceBucket(dnsConfidence),
+				Timestamp:       now,
+			}
+			if e.evidence {
+				d.Path = "dns:cname"
+				d.Evidence = name
+			}
+			detections = append(detections, d)
+		}
+	}
+	return detections, err
+}
+
+// dnsName returns the host of a target domain, or "" if it cannot have DNS records
+// (IP addresses, placeholders such as "unknown", file names).
+func dnsName(domain string) string {
+	host := strings.ToLower(strings.TrimSuffix(domain, "."))
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if !strings.Contains(host, ".") || net.ParseIP(host) != nil {
+		return ""
+	}
+	return host
+}
+
+// hasDomainSuffix reports whether name is suffix or a subdomain of it.
+func hasDomainSuffix(name, suffix string) bool {
+	return name == suffix || strings.HasSuffix(name, "."+suffix)
+}
+
+// chain returns the CNAME targets of a domain, in resolution order, from the
+// cache. Failed lookups (timeouts, unreachable resolver) are not kept, so a
+// later target on the same domain tries again.
+func (e *DNSEngine) chain(domain string) ([]string, error) {
+	entry, ok := e.cache.get(domain)
+	if !ok {
+		entry = e.cache.add(domain, &dnsEntry{})
+	}
+	entry.once.Do(func() {
+		entry.chain, entry.err = e.resolveChain(domain)
+		if entry.err != nil {
+			e.cache.remove(domain)
+		}
+	})
+	return entry.chain, entry.err
+}
+
+// resolveChain follows the CNAME records of a domain one hop at a time, so
+// intermediate names (often the most telling ones) are kept.
+func (e *DNSEngine) resolveChain(domain string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), e.timeout*maxCNAMEHops)
+	defer cancel()
+
+	if e.server == "" {
+		cname, err := net.DefaultResolver.LookupCNAME(ctx, domain)
+		if err != nil {
+			return nil, nil // No record: nothing to match
+		}
+		cname = strings.ToLower(strings.TrimSuffix(cname, "."))
+		if cname == domain {
+			return nil, nil
+		}
+		return []string{cname}, nil
+	}
+
+	var chain []string
+	name := domain
+	for i := 0; i < maxCNAMEHops; i++ {
+		next, err := e.queryCNAME(ctx, name)
+		if err != nil {
+			return chain, err
+		}
+		if next == "" || next == name {
+			break
+		}
+		chain = append(chain, next)
+		name = next
+	}
+	return chain, nil
+}
+
+// queryCNAME asks the resolver for the CNAME record of name. It returns "" if
+// the name has none.
+func (e *DNSEngine) queryCNAME(ctx context.Context, name string) (string, error) {
+	qname, err := dnsmessage.NewName(name + ".")
+	if err != nil {
+		return "", fmt.Errorf("invalid domain %q: %w", name, err)
+	}
+
+	id := uint16(rand.Uint32())
+	query := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: id, RecursionDesired: true},
+		Questions: []dnsmessage.Question{{Name: qname, Type: dnsmessage.TypeCNAME, Class: dnsmessage.ClassINET}},
+	}
+	packed, err := query.Pack()
+	if err != nil {
+		return "", err
+	}
+
+	dialer := net.Dialer{Timeout: e.timeout}
+	conn, err := dialer.DialContext(ctx, "udp", e.server)
+	if err != nil {
+		return "", fmt.Errorf("dns: %w", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(e.timeout))
+
+	if _, err := conn.Write(packed); err != nil {
+		return "", fmt.Errorf("dns: %w", err)
+	}
+
+	buf := make([]byte, 1232)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return "", fmt.Errorf("dns: %w", err)
+		}
+		var resp dnsmessage.Message
+		if err := resp.Unpack(buf[:n]); err != nil || resp.ID != id || !resp.Response {
+			continue // Stray or malformed packet
+		}
+		if resp.RCode != dnsmessage.RCodeSuccess {
+			return "", nil // NXDOMAIN, SERVFAIL, ...: end of the chain
+		}
+		for _, answer := range resp.Answers {
+			if cname, ok := answer.Body.(*dnsmessage.CNAMEResource); ok && strings.EqualFold(answer.Header.Name.String(), qname.String()) {
+				return strings.ToLower(strings.TrimSuffix(cname.CNAME.String(), ".")), nil
+			}
+		}
+		return "", nil
+	}
+}
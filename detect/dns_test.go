@@ -0,0 +1,154 @@
+package detect
+
+import (
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Abhaythakor/hyperwapp/model"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// fakeDNSServer answers CNAME queries from a fixed table over UDP. Queries for
+// names starting with "timeout." are never answered.
+func fakeDNSServer(t *testing.T, records map[string]string) (string, *atomic.Int32) {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	queries := &atomic.Int32{}
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			var query dnsmessage.Message
+			if err := query.Unpack(buf[:n]); err != nil || len(query.Questions) != 1 {
+				continue
+			}
+			queries.Add(1)
+
+			question := query.Questions[0]
+			if strings.HasPrefix(question.Name.String(), "timeout.") {
+				continue
+			}
+			resp := dnsmessage.Message{
+				Header:    dnsmessage.Header{ID: query.ID, Response: true, RecursionAvailable: true},
+				Questions: query.Questions,
+			}
+			name := strings.TrimSuffix(question.Name.String(), ".")
+			if target, ok := records[name]; ok {
+				resp.Answers = []dnsmessage.Resource{{
+					Header: dnsmessage.ResourceHeader{Name: question.Name, Type: dnsmessage.TypeCNAME, Class: dnsmessage.ClassINET, TTL: 60},
+					Body:   &dnsmessage.CNAMEResource{CNAME: dnsmessage.MustNewName(target + ".")},
+				}}
+			} else if !strings.HasSuffix(name, "example.com") {
+				resp.RCode = dnsmessage.RCodeNameError
+			}
+			packed, _ := resp.Pack()
+			conn.WriteTo(packed, addr)
+		}
+	}()
+	return conn.LocalAddr().String(), queries
+}
+
+func TestDNSEngine(t *testing.T) {
+	server, queries := fakeDNSServer(t, map[string]string{
+		"www.example.com":               "www.example.com.edgekey.net",
+		"www.example.com.edgekey.net":   "e1234.a.akamaiedge.net",
+		"app.example.com":               "example-app.herokudns.com",
+		"shop.example.com":              "shops.myshopify.com",
+		"loop.example.com":              "loop.example.com",
+		"assets.example.com":            "d111111abcdef8.cloudfront.net",
+		"d111111abcdef8.cloudfront.net": "unrelated.example.net",
+	})
+
+	engine, err := NewDNSEngine(EngineOptions{DNSResolver: server, DNSTimeout: time.Second, Evidence: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		domain   string
+		want     string
+		evidence string
+	}{
+		{"www.example.com", "Akamai", "www.example.com.edgekey.net"},
+		{"app.example.com:8443", "Heroku", "example-app.herokudns.com"},
+		{"shop.example.com", "Shopify", "shops.myshopify.com"},
+		{"assets.example.com", "Amazon CloudFront", "d111111abcdef8.cloudfront.net"},
+		{"plain.example.com", "", ""},
+		{"loop.example.com", "", ""},
+		{"unknown", "", ""},
+		{"192.0.2.1", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.domain, func(t *testing.T) {
+			detections, err := engine.DetectTarget(model.Target{Domain: tt.domain})
+			if err != nil {
+				t.Fatalf("DetectTarget() error = %v", err)
+			}
+			if tt.want == "" {
+				if len(detections) != 0 {
+					t.Errorf("DetectTarget() = %v, want none", detections)
+				}
+				return
+			}
+			if len(detections) != 1 || detections[0].Technology != tt.want || detections[0].Evidence != tt.evidence || detections[0].Source != model.SourceDNS {
+				t.Errorf("DetectTarget() = %+v, want %s via %s", detections, tt.want, tt.evidence)
+			}
+		})
+	}
+
+	// Repeated targets on the same domain are served from the cache
+	before := queries.Load()
+	for i := 0; i < 10; i++ {
+		engine.DetectTarget(model.Target{Domain: "www.example.com"})
+	}
+	if after := queries.Load(); after != before {
+		t.Errorf("cached domain sent %d more queries", after-before)
+	}
+
+	// The cache is bounded: evicted domains are resolved again
+	engine.cache = newLRU[string, *dnsEntry](1)
+	engine.DetectTarget(model.Target{Domain: "www.example.com"})
+	engine.DetectTarget(model.Target{Domain: "app.example.com"})
+	before = queries.Load()
+	engine.DetectTarget(model.Target{Domain: "www.example.com"})
+	if after := queries.Load(); after == before {
+		t.Error("evicted domain was not resolved again")
+	}
+}
+
+func TestDNSEngineLookupFailure(t *testing.T) {
+	server, queries := fakeDNSServer(t, map[string]string{
+		"media.example.com": "timeout.media.cloudfront.net",
+	})
+	engine, err := NewDNSEngine(EngineOptions{DNSResolver: server, DNSTimeout: 100 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The hop after the CloudFront name times out: the names resolved so far still match
+	detections, err := engine.DetectTarget(model.Target{Domain: "media.example.com"})
+	if err == nil {
+		t.Error("DetectTarget() error = nil, want the timeout")
+	}
+	if len(detections) != 1 || detections[0].Technology != "Amazon CloudFront" {
+		t.Errorf("DetectTarget() = %+v, want Amazon CloudFront", detections)
+	}
+
+	// Failed lookups are not cached
+	before := queries.Load()
+	engine.DetectTarget(model.Target{Domain: "media.example.com"})
+	if after := queries.Load(); after == before {
+		t.Error("failed domain was not resolved again")
+	}
+}
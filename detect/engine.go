@@ -5,6 +5,7 @@ import (
 	"crypto/sha256"
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -28,6 +29,8 @@ var (
 // wappalyzerClient defines the interface for the Wappalyzer client.
 type wappalyzerClient interface {
 	Fingerprint(headers map[string][]string, data []byte) map[string]struct{}
+	GetFingerprints() *wappalyzer.Fingerprints
+	GetCompiledFingerprints() *wappalyzer.CompiledFingerprints
 }
 
 // Engine defines the interface for a technology detection engine.
@@ -37,31 +40,167 @@ type Engine interface {
 
 // WappalyzerEngine implements the Engine interface using wappalyzergo.
 type WappalyzerEngine struct {
-	client    wappalyzerClient
-	bodyCache sync.Map // [32]byte -> map[string]struct{}
+	client        wappalyzerClient
+	bodyCache     *BodyCache // nil when disabled
+	categoryCache sync.Map // technology -> []string
+	patternCache  sync.Map // technology -> *appPatterns (evidence mode)
+	evidence      bool
+	source        *FingerprintSource
+	packOf        map[string]string // technology -> user pack name
+}
+
+// EngineOptions configures a WappalyzerEngine.
+type EngineOptions struct {
+	FingerprintsPath string // Explicit fingerprints file (--fingerprints); empty for default resolution
+	PacksDir         string // Directory of user-defined fingerprint packs merged with the built-in set
+	BodyCacheSize    int    // Maximum cached body scan results; 0 uses DefaultBodyCacheSize
+	NoBodyCache      bool   // Disable the body cache entirely
+	Evidence         bool   // Record match locations (see SetEvidenceMode)
+	FaviconDBPath    string // Favicon hash database (favicon engine); empty for default resolution
+	DNSResolver      string // Resolver address for the dns engine; empty for /etc/resolv.conf
+	DNSTimeout       time.Duration
 }
 
 // NewWappalyzerEngine creates and initializes a new WappalyzerEngine.
-func NewWappalyzerEngine() (*WappalyzerEngine, error) {
-	wappalyzerClient, err := wappalyzer.New()
+func NewWappalyzerEngine(opts EngineOptions) (*WappalyzerEngine, error) {
+	source, err := ResolveFingerprints(opts.FingerprintsPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize wappalyzer: %w", err)
+		return nil, err
+	}
+
+	var (
+		wappalyzerClient *wappalyzer.Wappalyze
+		packOf           map[string]string
+	)
+	if opts.PacksDir != "" {
+		packs, err := LoadPacks(opts.PacksDir)
+		if err != nil {
+			return nil, err
+		}
+		wappalyzerClient, packOf, err = newClientWithPacks(source, packs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize wappalyzer with fingerprint packs: %w", err)
+		}
+	} else {
+		wappalyzerClient, err = newClient(source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize wappalyzer: %w", err)
+		}
 	}
-	return &WappalyzerEngine{
+
+	engine := &WappalyzerEngine{
 		client: wappalyzerClient,
-	}, nil
+		source: source,
+		packOf: packOf,
+	}
+	if !opts.NoBodyCache {
+		engine.bodyCache = NewBodyCache(opts.BodyCacheSize)
+	}
+	return engine, nil
+}
+
+// CacheStats returns the body cache counters, or false if the cache is disabled.
+func (e *WappalyzerEngine) CacheStats() (CacheStats, bool) {
+	if e.bodyCache == nil {
+		return CacheStats{}, false
+	}
+	return e.bodyCache.Stats(), true
+}
+
+// Source returns the fingerprints the engine was loaded from.
+func (e *WappalyzerEngine) Source() *FingerprintSource {
+	return e.source
+}
+
+// splitAppVersion splits a wappalyzergo result key ("WordPress:6.4.2") into the
+// technology name and its version. Some technology names contain a colon
+// themselves (e.g. "Re:amaze"), so known names are matched before splitting.
+// Implied technologies may also carry pattern modifiers ("Magento\;version:2"),
+// which are stripped; a fixed version modifier is kept as the version.
+func (e *WappalyzerEngine) splitAppVersion(key string) (string, string) {
+	if idx := strings.Index(key, `\;`); idx != -1 {
+		modifiers := key[idx:]
+		key = key[:idx]
+		for _, m := range strings.Split(modifiers, `\;`) {
+			if v, ok := strings.CutPrefix(m, "version:"); ok && v != "" && !strings.Contains(v, `\`) {
+				return key, v
+			}
+		}
+	}
+
+	apps := e.client.GetFingerprints().Apps
+	if _, ok := apps[key]; ok {
+		return key, ""
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] != ':' {
+			continue
+		}
+		if _, ok := apps[key[:i]]; ok {
+			return key[:i], key[i+1:]
+		}
+	}
+
+	// Unknown name (e.g. an implied technology without its own fingerprint)
+	name, version, _ := strings.Cut(key, ":")
+	return name, version
+}
+
+// pack returns the name of the fingerprint pack a technology was defined in.
+func (e *WappalyzerEngine) pack(tech string) string {
+	if name, ok := e.packOf[tech]; ok {
+		return name
+	}
+	return model.PackBuiltin
+}
+
+// categories returns the Wappalyzer category names of a technology.
+// FingerprintWithCats only resolves unversioned result keys, so the categories
+// are looked up by name from the same compiled fingerprints it uses.
+func (e *WappalyzerEngine) categories(tech string) []string {
+	if cached, ok := e.categoryCache.Load(tech); ok {
+		return cached.([]string)
+	}
+
+	var categories []string
+	if fingerprint, ok := e.client.GetCompiledFingerprints().Apps[tech]; ok {
+		categories = wappalyzer.AppInfoFromFingerprint(fingerprint).Categories
+		sort.Strings(categories)
+	}
+	e.categoryCache.Store(tech, categories)
+	return categories
+}
+
+// CPE returns the CPE 2.3 string the fingerprints define for a technology,
+// or "" if it has none.
+func (e *WappalyzerEngine) CPE(tech string) string {
+	if fingerprint, ok := e.client.GetFingerprints().Apps[tech]; ok {
+		return fingerprint.CPE
+	}
+	return ""
+}
+
+// CPEs returns every CPE defined by the fingerprints.
+func (e *WappalyzerEngine) CPEs() []string {
+	var cpes []string
+	for _, fingerprint := range e.client.GetFingerprints().Apps {
+		if fingerprint.CPE != "" {
+			cpes = append(cpes, fingerprint.CPE)
+		}
+	}
+	return cpes
 }
 
 // Detect identifies technologies based on headers and body.
 func (e *WappalyzerEngine) Detect(headers map[string][]string, body []byte, sourceHint string) ([]model.Detection, error) {
 	// Stage 1: Always scan headers (fast)
-	fingerprints := e.client.Fingerprint(headers, nil)
+	headerFingerprints := e.client.Fingerprint(headers, nil)
 
 	// Stage 2: Handle body scan
 	if sourceHint != model.SourceHeadersOnly && len(body) > 0 {
 		// Optimization: Check if it's a binary file first (fast)
-		if e.isBinaryResponse(headers, body) {
-			return e.wrapDetections(fingerprints, sourceHint), nil
+		if isBinaryResponse(headers, body) {
+			return e.wrapDetections(headerFingerprints, nil, headers, nil, sourceHint), nil
 		}
 
 		// Optimization 1: Semantic Pruning (Removes non-detectable bloat)
@@ -69,52 +208,65 @@ func (e *WappalyzerEngine) Detect(headers map[string][]string, body []byte, sour
 
 		// Optimization 2: Body Caching
 		var bodyFingerprints map[string]struct{}
-		if len(scanBody) > 1024 {
+		if e.bodyCache != nil && len(scanBody) > 1024 {
 			bodyHash := sha256.Sum256(scanBody)
-			if cached, ok := e.bodyCache.Load(bodyHash); ok {
+			if cached, ok := e.bodyCache.Get(bodyHash); ok {
 				// Cache hit
-				bodyFingerprints = cached.(map[string]struct{})
+				bodyFingerprints = cached
 			} else {
 				// Cache miss: Scan body only (once!)
 				bodyFingerprints = e.client.Fingerprint(nil, scanBody)
-				e.bodyCache.Store(bodyHash, bodyFingerprints)
+				e.bodyCache.Put(bodyHash, bodyFingerprints)
 			}
 		} else {
 			// Small body: Scan directly
 			bodyFingerprints = e.client.Fingerprint(nil, scanBody)
 		}
 
-		// Merge body fingerprints into combined results
-		for k := range bodyFingerprints {
-			fingerprints[k] = struct{}{}
-		}
+		return e.wrapDetections(headerFingerprints, bodyFingerprints, headers, scanBody, sourceHint), nil
 	}
 
-	return e.wrapDetections(fingerprints, sourceHint), nil
+	return e.wrapDetections(headerFingerprints, nil, headers, nil, sourceHint), nil
+}
+
+// SetEvidenceMode enables recording where each technology matched (header, cookie,
+// meta tag, script src or HTML pattern). It re-evaluates the patterns of every
+// detected technology, so it is off by default.
+func (e *WappalyzerEngine) SetEvidenceMode(enabled bool) {
+	e.evidence = enabled
+}
+
+// binaryContentTypes are Content-Type prefixes of non-textual formats.
+var binaryContentTypes = []string{
+	"image/", "audio/", "video/", "font/",
+	"application/octet-stream", "application/pdf",
+	"application/zip", "application/x-gzip", "application/x-rar",
+}
+
+// IsBinaryContentType reports whether a Content-Type names a binary format
+// whose body is not worth scanning (or downloading).
+func IsBinaryContentType(contentType string) bool {
+	ct := strings.ToLower(strings.TrimSpace(contentType))
+	for _, prefix := range binaryContentTypes {
+		if strings.HasPrefix(ct, prefix) {
+			return true
+		}
+	}
+	return false
 }
 
 // isBinaryResponse checks if the response is a non-textual format that should skip body scanning.
-func (e *WappalyzerEngine) isBinaryResponse(headers map[string][]string, body []byte) bool {
+func isBinaryResponse(headers map[string][]string, body []byte) bool {
 	// 1. Check Content-Type header
 	ct := ""
 	if v, ok := headers["Content-Type"]; ok && len(v) > 0 {
-		ct = strings.ToLower(v[0])
+		ct = v[0]
 	} else if v, ok := headers["content-type"]; ok && len(v) > 0 {
-		ct = strings.ToLower(v[0])
+		ct = v[0]
 	}
 
-	if ct != "" {
-		// Skip common binary formats
-		binaryPrefixes := []string{
-			"image/", "audio/", "video/", "font/",
-			"application/octet-stream", "application/pdf",
-			"application/zip", "application/x-gzip", "application/x-rar",
-		}
-		for _, prefix := range binaryPrefixes {
-			if strings.HasPrefix(ct, prefix) {
-				return true
-			}
-		}
+	if ct != "" && IsBinaryContentType(ct) {
+		return true
 	}
 
 	// 2. Heuristic: Check for null bytes in the first 512 bytes of the body
@@ -169,8 +321,8 @@ func (e *WappalyzerEngine) pruneBody(body []byte) []byte {
 	return pruned
 }
 
-func (e *WappalyzerEngine) wrapDetections(fingerprints map[string]struct{}, sourceHint string) []model.Detection {
-	detections := make([]model.Detection, 0, len(fingerprints))
+func (e *WappalyzerEngine) wrapDetections(headerFingerprints, bodyFingerprints map[string]struct{}, headers map[string][]string, scanBody []byte, sourceHint string) []model.Detection {
+	detections := make([]model.Detection, 0, len(headerFingerprints)+len(bodyFingerprints))
 	now := time.Now().UTC()
 
 	source := model.SourceWappalyzer
@@ -180,15 +332,40 @@ func (e *WappalyzerEngine) wrapDetections(fingerprints map[string]struct{}, sour
 		source = model.SourceBodyOnly
 	}
 
-	for tech := range fingerprints {
+	// Header and body stages may report the same technology with and without a
+	// version, so keep one detection per name and prefer the versioned one.
+	index := make(map[string]int, len(headerFingerprints)+len(bodyFingerprints))
+	add := func(key, stage string) {
+		tech, version := e.splitAppVersion(key)
+		if i, ok := index[tech]; ok {
+			if detections[i].Version == "" {
+				detections[i].Version = version
+			}
+			if detections[i].Stage != stage {
+				detections[i].Stage = StageBoth
+			}
+			return
+		}
+		index[tech] = len(detections)
 		detections = append(detections, model.Detection{
 			Technology: tech,
+			Version:    version,
+			Categories: e.categories(tech),
 			Source:     source,
+			Stage:      stage,
+			Pack:       e.pack(tech),
 			Path:       "fingerprint",
 			Evidence:   "wappalyzergo",
-			Confidence: "high",
 			Timestamp:  now,
 		})
 	}
+	for key := range headerFingerprints {
+		add(key, StageHeader)
+	}
+	for key := range bodyFingerprints {
+		add(key, StageBody)
+	}
+
+	e.annotate(detections, headers, scanBody)
 	return detections
 }
@@ -1,12 +1,13 @@
 package detect
 
 import (
+	"strings"
 	"testing"
 	"github.com/Abhaythakor/hyperwapp/model"
 )
 
 func BenchmarkDetect(b *testing.B) {
-	engine, _ := NewWappalyzerEngine()
+	engine, _ := NewWappalyzerEngine(EngineOptions{})
 	headers := map[string][]string{
 		"Server": {"Apache"},
 		"X-Powered-By": {"PHP/7.4"},
@@ -18,3 +19,173 @@ func BenchmarkDetect(b *testing.B) {
 		_, _ = engine.Detect(headers, body, model.SourceWappalyzer)
 	}
 }
+
+func TestSplitAppVersion(t *testing.T) {
+	engine, err := NewWappalyzerEngine(EngineOptions{})
+	if err != nil {
+		t.Fatalf("NewWappalyzerEngine failed: %v", err)
+	}
+
+	tests := []struct {
+		key         string
+		wantTech    string
+		wantVersion string
+	}{
+		{"WordPress:6.4.2", "WordPress", "6.4.2"},
+		{"WordPress", "WordPress", ""},
+		{"Re:amaze", "Re:amaze", ""},
+		{`PHP\;confidence:75`, "PHP", ""},
+		{`Magento\;version:2`, "Magento", "2"},
+	}
+
+	for _, tt := range tests {
+		tech, version := engine.splitAppVersion(tt.key)
+		if tech != tt.wantTech || version != tt.wantVersion {
+			t.Errorf("splitAppVersion(%q) = (%q, %q); want (%q, %q)", tt.key, tech, version, tt.wantTech, tt.wantVersion)
+		}
+	}
+}
+
+func TestDetectVersion(t *testing.T) {
+	engine, _ := NewWappalyzerEngine(EngineOptions{})
+	headers := map[string][]string{
+		"X-Powered-By": {"PHP/7.4.3"},
+	}
+
+	detections, err := engine.Detect(headers, nil, model.SourceWappalyzer)
+	if err != nil {
+		t.Fatalf("Detect failed: %v", err)
+	}
+
+	for _, d := range detections {
+		if d.Technology == "PHP" {
+			if d.Version != "7.4.3" {
+				t.Errorf("Expected PHP version '7.4.3', got '%s'", d.Version)
+			}
+			return
+		}
+	}
+	t.Errorf("Expected PHP to be detected, got %v", detections)
+}
+
+func TestDetectSourceHint(t *testing.T) {
+	engine, _ := NewWappalyzerEngine(EngineOptions{})
+	headers := map[string][]string{"Server": {"nginx/1.25.3"}}
+	body := []byte(`<html><head><meta name="generator" content="WordPress 6.4.2"></head></html>`)
+
+	tests := []struct {
+		hint       string
+		wantSource string
+		wantNginx  bool
+		wantWP     bool
+	}{
+		{model.SourceWappalyzer, model.SourceWappalyzer, true, true},
+		{model.SourceHeadersOnly, model.SourceHeadersOnly, true, false},
+		{model.SourceBodyOnly, model.SourceBodyOnly, true, true},
+	}
+	for _, tt := range tests {
+		detections, err := engine.Detect(headers, body, tt.hint)
+		if err != nil {
+			t.Fatalf("Detect(%s) failed: %v", tt.hint, err)
+		}
+		found := make(map[string]bool)
+		for _, d := range detections {
+			found[d.Technology] = true
+			if d.Source != tt.wantSource {
+				t.Errorf("Detect(%s): %s has source %q, want %q", tt.hint, d.Technology, d.Source, tt.wantSource)
+			}
+		}
+		if found["Nginx"] != tt.wantNginx || found["WordPress"] != tt.wantWP {
+			t.Errorf("Detect(%s) = %v", tt.hint, detections)
+		}
+	}
+}
+
+func TestDetectEvidence(t *testing.T) {
+	engine, _ := NewWappalyzerEngine(EngineOptions{})
+	engine.SetEvidenceMode(true)
+
+	headers := map[string][]string{
+		"Server": {"Apache/2.4.41 (Ubuntu)"},
+	}
+	body := []byte(`<html><head><meta name="generator" content="WordPress 6.4.2"></head><body></body></html>`)
+
+	detections, err := engine.Detect(headers, body, model.SourceWappalyzer)
+	if err != nil {
+		t.Fatalf("Detect failed: %v", err)
+	}
+
+	found := make(map[string]model.Detection)
+	for _, d := range detections {
+		found[d.Technology] = d
+	}
+
+	apache, ok := found["Apache HTTP Server"]
+	if !ok {
+		t.Fatalf("Expected Apache HTTP Server to be detected, got %v", detections)
+	}
+	if apache.Stage != StageHeader || apache.Path != "header:server" || !strings.Contains(apache.Evidence, "Apache/2.4.41") {
+		t.Errorf("Unexpected Apache evidence: stage=%s path=%s evidence=%s", apache.Stage, apache.Path, apache.Evidence)
+	}
+
+	wp, ok := found["WordPress"]
+	if !ok {
+		t.Fatalf("Expected WordPress to be detected, got %v", detections)
+	}
+	if wp.Stage != StageBody || wp.Path != "meta:generator" || !strings.Contains(wp.Evidence, "WordPress 6.4.2") {
+		t.Errorf("Unexpected WordPress evidence: stage=%s path=%s evidence=%s", wp.Stage, wp.Path, wp.Evidence)
+	}
+}
+
+func TestScoreWithBonus(t *testing.T) {
+	tests := []struct {
+		score int
+		stage string
+		want  int
+	}{
+		{100, StageHeader, 100},
+		{50, StageBody, 50},
+		{50, StageBoth, 60},
+		{95, StageBoth, 100},
+		{175, StageHeader, 100},
+	}
+
+	for _, tt := range tests {
+		if got := scoreWithBonus(tt.score, tt.stage); got != tt.want {
+			t.Errorf("scoreWithBonus(%d, %s) = %d; want %d", tt.score, tt.stage, got, tt.want)
+		}
+	}
+}
+
+func TestDetectConfidence(t *testing.T) {
+	engine, _ := NewWappalyzerEngine(EngineOptions{})
+	headers := map[string][]string{
+		"X-Powered-By": {"PHP/7.4.3"},
+	}
+
+	detections, _ := engine.Detect(headers, nil, model.SourceWappalyzer)
+	for _, d := range detections {
+		if d.ConfidenceScore <= 0 || d.ConfidenceScore > 100 {
+			t.Errorf("%s: confidence score %d out of range", d.Technology, d.ConfidenceScore)
+		}
+		if d.Confidence != model.ConfidenceBucket(d.ConfidenceScore) {
+			t.Errorf("%s: confidence bucket %s does not match score %d", d.Technology, d.Confidence, d.ConfidenceScore)
+		}
+	}
+}
+
+func TestIsBinaryContentType(t *testing.T) {
+	tests := map[string]bool{
+		"image/png":                true,
+		"Application/PDF":          true,
+		"application/octet-stream": true,
+		"text/html; charset=utf-8": false,
+		"application/javascript":   false,
+		"":                         false,
+	}
+	for ct, want := range tests {
+		if got := IsBinaryContentType(ct); got != want {
+			t.Errorf("IsBinaryContentType(%q) = %v, want %v", ct, got, want)
+		}
+	}
+}
@@ -0,0 +1,342 @@
+package detect
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	wappalyzer "github.com/projectdiscovery/wappalyzergo"
+)
+
+const (
+	// Match locations reported in model.Detection.Path
+	PartHeader    = "header"
+	PartCookie    = "cookie"
+	PartMeta      = "meta"
+	PartScriptSrc = "script-src"
+	PartHTML      = "html"
+	PartImplied   = "implied"
+
+	// Detection stages reported in model.Detection.Stage
+	StageHeader = "header"
+	StageBody   = "body"
+	StageBoth   = "header+body"
+
+	maxEvidenceLen = 128
+)
+
+var (
+	// Lightweight tag extraction for evidence (the fingerprinting itself uses a full tokenizer)
+	metaTagRegex   = regexp.MustCompile(`(?is)<meta\s[^>]*>`)
+	scriptSrcRegex = regexp.MustCompile(`(?is)<script\s[^>]*\bsrc\s*=\s*["']?([^"'\s>]+)`)
+	attrRegex      = regexp.MustCompile(`(?is)\b(name|property|content)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s>]+))`)
+)
+
+// evidencePattern is a wappalyzer pattern compiled so that the matched text can be recovered.
+type evidencePattern struct {
+	regex      *regexp.Regexp // nil when the pattern only checks for presence
+	confidence int
+	version    string
+}
+
+// appPatterns holds the compiled patterns of a single technology.
+type appPatterns struct {
+	headers   map[string]*evidencePattern // lowercased header name -> pattern
+	cookies   map[string]*evidencePattern // lowercased cookie name -> pattern
+	meta      map[string][]*evidencePattern
+	scriptSrc []*evidencePattern
+	html      []*evidencePattern
+	implies   []string
+	partial   bool // True if any pattern has a confidence below 100
+}
+
+// patternMatch describes where a technology pattern matched.
+type patternMatch struct {
+	part       string // header, cookie, meta, script-src, html
+	key        string // header/cookie/meta name, empty for script-src and html
+	matched    string // matched substring
+	confidence int
+	version    string
+}
+
+// location renders the match location for model.Detection.Path (e.g. "header:server").
+func (m patternMatch) location() string {
+	if m.key == "" {
+		return m.part
+	}
+	return m.part + ":" + m.key
+}
+
+// compileEvidencePattern mirrors wappalyzer.ParsePattern, but keeps the regex
+// so the matched substring can be reported.
+func compileEvidencePattern(pattern string) (*evidencePattern, error) {
+	parts := strings.Split(pattern, `\;`)
+	p := &evidencePattern{confidence: 100}
+
+	for i, part := range parts {
+		if i == 0 {
+			if part == "" {
+				continue
+			}
+			// Same quantifier limits as wappalyzergo, keeping version capture groups intact
+			re := strings.ReplaceAll(part, `(\d+(?:\.\d+)+)`, "__verCap1__")
+			re = strings.ReplaceAll(re, `((?:\d+\.)+\d+)`, "__verCap2__")
+			re = strings.ReplaceAll(re, `\+`, "__escapedPlus__")
+			re = strings.ReplaceAll(re, "+", "{1,250}")
+			re = strings.ReplaceAll(re, "*", "{0,250}")
+			re = strings.ReplaceAll(re, "__escapedPlus__", `\+`)
+			re = strings.ReplaceAll(re, "__verCap1__", `(\d{1,20}(?:\.\d{1,20}){1,20})`)
+			re = strings.ReplaceAll(re, "__verCap2__", `((?:\d{1,20}\.){1,20}\d{1,20})`)
+
+			compiled, err := regexp.Compile("(?i)" + re)
+			if err != nil {
+				return nil, err
+			}
+			p.regex = compiled
+			continue
+		}
+
+		key, value, ok := strings.Cut(part, ":")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "confidence":
+			if conf, err := strconv.Atoi(value); err == nil {
+				p.confidence = conf
+			}
+		case "version":
+			p.version = value
+		}
+	}
+	return p, nil
+}
+
+// evaluate returns the matched substring and extracted version, if the pattern matches.
+func (p *evidencePattern) evaluate(target string) (string, string, bool) {
+	if p.regex == nil {
+		return target, "", true
+	}
+
+	loc := p.regex.FindStringSubmatchIndex(target)
+	if loc == nil {
+		return "", "", false
+	}
+
+	version := p.version
+	for i := 1; i*2 < len(loc); i++ {
+		sub := ""
+		if loc[i*2] >= 0 {
+			sub = target[loc[i*2]:loc[i*2+1]]
+		}
+		version = strings.ReplaceAll(version, `\`+strconv.Itoa(i), sub)
+	}
+	// Ternary expressions ("\1?v1:v2") are left to wappalyzergo; only keep plain versions
+	if strings.ContainsAny(version, "?\\") {
+		version = ""
+	}
+	return target[loc[0]:loc[1]], strings.TrimSpace(version), true
+}
+
+// compileAppPatterns compiles every pattern of a fingerprint. Invalid patterns are skipped,
+// as wappalyzergo does.
+func compileAppPatterns(fingerprint *wappalyzer.Fingerprint) *appPatterns {
+	compiled := &appPatterns{
+		headers: make(map[string]*evidencePattern, len(fingerprint.Headers)),
+		cookies: make(map[string]*evidencePattern, len(fingerprint.Cookies)),
+		meta:    make(map[string][]*evidencePattern, len(fingerprint.Meta)),
+		implies: fingerprint.Implies,
+	}
+
+	for name, pattern := range fingerprint.Headers {
+		if p, err := compileEvidencePattern(pattern); err == nil {
+			compiled.headers[strings.ToLower(name)] = p
+		}
+	}
+	for name, pattern := range fingerprint.Cookies {
+		if p, err := compileEvidencePattern(pattern); err == nil {
+			compiled.cookies[strings.ToLower(name)] = p
+		}
+	}
+	for name, patterns := range fingerprint.Meta {
+		for _, pattern := range patterns {
+			if p, err := compileEvidencePattern(pattern); err == nil {
+				compiled.meta[strings.ToLower(name)] = append(compiled.meta[strings.ToLower(name)], p)
+			}
+		}
+	}
+	for _, pattern := range fingerprint.ScriptSrc {
+		if p, err := compileEvidencePattern(pattern); err == nil {
+			compiled.scriptSrc = append(compiled.scriptSrc, p)
+		}
+	}
+	for _, pattern := range fingerprint.HTML {
+		if p, err := compileEvidencePattern(pattern); err == nil {
+			compiled.html = append(compiled.html, p)
+		}
+	}
+
+	check := func(p *evidencePattern) {
+		if p.confidence < 100 {
+			compiled.partial = true
+		}
+	}
+	for _, p := range compiled.headers {
+		check(p)
+	}
+	for _, p := range compiled.cookies {
+		check(p)
+	}
+	for _, patterns := range compiled.meta {
+		for _, p := range patterns {
+			check(p)
+		}
+	}
+	for _, p := range compiled.scriptSrc {
+		check(p)
+	}
+	for _, p := range compiled.html {
+		check(p)
+	}
+	return compiled
+}
+
+// appPatterns returns the compiled patterns of a technology, compiling them on first use.
+func (e *WappalyzerEngine) appPatterns(tech string) *appPatterns {
+	if cached, ok := e.patternCache.Load(tech); ok {
+		return cached.(*appPatterns)
+	}
+
+	fingerprint, ok := e.client.GetFingerprints().Apps[tech]
+	if !ok {
+		return nil
+	}
+	compiled := compileAppPatterns(fingerprint)
+	e.patternCache.Store(tech, compiled)
+	return compiled
+}
+
+// matchHeaders evaluates the header and cookie patterns of a technology.
+func (e *WappalyzerEngine) matchHeaders(tech string, headers map[string][]string) []patternMatch {
+	patterns := e.appPatterns(tech)
+	if patterns == nil || len(headers) == 0 {
+		return nil
+	}
+
+	var matches []patternMatch
+	for name, values := range headers {
+		lowerName := strings.ToLower(name)
+		value := strings.Join(values, ", ")
+
+		if p, ok := patterns.headers[lowerName]; ok {
+			if matched, version, ok := p.evaluate(value); ok {
+				matches = append(matches, patternMatch{PartHeader, lowerName, matched, p.confidence, version})
+			}
+		}
+
+		if lowerName != "set-cookie" || len(patterns.cookies) == 0 {
+			continue
+		}
+		for _, cookie := range values {
+			pair, _, _ := strings.Cut(cookie, ";")
+			cookieName, cookieValue, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if !ok {
+				continue
+			}
+			if p, ok := patterns.cookies[strings.ToLower(cookieName)]; ok {
+				if matched, version, ok := p.evaluate(cookieValue); ok {
+					matches = append(matches, patternMatch{PartCookie, cookieName, matched, p.confidence, version})
+				}
+			}
+		}
+	}
+	return matches
+}
+
+// matchBody evaluates the meta, script src and HTML patterns of a technology.
+func (e *WappalyzerEngine) matchBody(tech string, body []byte) []patternMatch {
+	patterns := e.appPatterns(tech)
+	if patterns == nil || len(body) == 0 {
+		return nil
+	}
+
+	var matches []patternMatch
+	content := string(body)
+
+	if len(patterns.meta) > 0 {
+		for _, tag := range metaTagRegex.FindAllString(content, -1) {
+			var name, value string
+			for _, attr := range attrRegex.FindAllStringSubmatch(tag, -1) {
+				v := attr[2] + attr[3] + attr[4]
+				if strings.EqualFold(attr[1], "content") {
+					value = v
+				} else {
+					name = strings.ToLower(v)
+				}
+			}
+			for _, p := range patterns.meta[name] {
+				if matched, version, ok := p.evaluate(value); ok {
+					matches = append(matches, patternMatch{PartMeta, name, matched, p.confidence, version})
+					break
+				}
+			}
+		}
+	}
+
+	if len(patterns.scriptSrc) > 0 {
+		for _, src := range scriptSrcRegex.FindAllStringSubmatch(content, -1) {
+			for _, p := range patterns.scriptSrc {
+				if matched, version, ok := p.evaluate(src[1]); ok {
+					matches = append(matches, patternMatch{PartScriptSrc, "", matched, p.confidence, version})
+					break
+				}
+			}
+		}
+	}
+
+	for _, p := range patterns.html {
+		if matched, version, ok := p.evaluate(content); ok {
+			matches = append(matches, patternMatch{PartHTML, "", matched, p.confidence, version})
+		}
+	}
+	return matches
+}
+
+// impliedBy returns the first detected technology that implies tech, along with the
+// confidence modifier of the implication (100 when none is given).
+func (e *WappalyzerEngine) impliedBy(tech string, detected []string) (string, int) {
+	for _, other := range detected {
+		if other == tech {
+			continue
+		}
+		patterns := e.appPatterns(other)
+		if patterns == nil {
+			continue
+		}
+		for _, implied := range patterns.implies {
+			name, modifiers, _ := strings.Cut(implied, `\;`)
+			if name != tech {
+				continue
+			}
+			confidence := 100
+			for _, m := range strings.Split(modifiers, `\;`) {
+				if v, ok := strings.CutPrefix(m, "confidence:"); ok {
+					if conf, err := strconv.Atoi(v); err == nil {
+						confidence = conf
+					}
+				}
+			}
+			return other, confidence
+		}
+	}
+	return "", 0
+}
+
+// truncateEvidence keeps evidence strings short enough for tabular output.
+func truncateEvidence(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if len(s) > maxEvidenceLen {
+		return s[:maxEvidenceLen] + "..."
+	}
+	return s
+}
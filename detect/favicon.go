@@ -0,0 +1,284 @@
+package detect
+
+import (
+	_ "embed"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math/bits"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Abhaythakor/hyperwapp/model"
+	"github.com/Abhaythakor/hyperwapp/util"
+)
+
+// EngineFavicon is the registry name of the favicon hash engine.
+const EngineFavicon = "favicon"
+
+const (
+	faviconDBURL      = "https://raw.githubusercontent.com/Abhaythakor/hyperwapp/main/detect/data/favicons.json"
+	faviconConfidence = 90
+	maxIconLinks      = 3
+)
+
+//go:embed data/favicons.json
+var embeddedFaviconDB []byte
+
+var (
+	iconLinkRegex = regexp.MustCompile(`(?i)<link\s[^>]*rel=["']?(?:shortcut\s+)?(?:icon|apple-touch-icon)["'\s>][^>]*>`)
+	hrefRegex     = regexp.MustCompile(`(?i)\shref=["']?([^"'\s>]+)`)
+)
+
+func init() {
+	RegisterEngine(EngineFavicon, func(opts EngineOptions) (Engine, error) {
+		return NewFaviconEngine(opts)
+	})
+}
+
+// FaviconEntry maps a favicon hash to a product.
+type FaviconEntry struct {
+	Hash       int32    `json:"hash"`
+	Product    string   `json:"product"`
+	Categories []string `json:"categories,omitempty"`
+}
+
+type faviconDB struct {
+	Favicons []FaviconEntry `json:"favicons"`
+}
+
+// ParseFaviconDB decodes a favicon database and indexes it by hash.
+func ParseFaviconDB(data []byte) (map[int32]FaviconEntry, error) {
+	var db faviconDB
+	if err := json.Unmarshal(data, &db); err != nil {
+		return nil, fmt.Errorf("invalid favicon database: %w", err)
+	}
+	if len(db.Favicons) == 0 {
+		return nil, fmt.Errorf("invalid favicon database: no \"favicons\" entries")
+	}
+
+	index := make(map[int32]FaviconEntry, len(db.Favicons))
+	for i, entry := range db.Favicons {
+		if entry.Product == "" {
+			return nil, fmt.Errorf("invalid favicon database: entry %d has no product", i)
+		}
+		index[entry.Hash] = entry
+	}
+	return index, nil
+}
+
+// GetFaviconDBPath returns the local path where --update stores the favicon database.
+func GetFaviconDBPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "hyperwapp", "favicons.json"), nil
+}
+
+// UpdateFaviconDB downloads the latest favicon database.
+func UpdateFaviconDB() error {
+	util.Info("Updating favicon hash database...")
+
+	path, err := GetFaviconDBPath()
+	if err != nil {
+		return fmt.Errorf("could not determine favicon database path: %w", err)
+	}
+	count, err := downloadValidated(faviconDBURL, path, func(data []byte) (int, error) {
+		index, err := ParseFaviconDB(data)
+		return len(index), err
+	})
+	if err != nil {
+		return err
+	}
+
+	util.Info("Favicon database updated to %s (%d hashes)", path, count)
+	return nil
+}
+
+// loadFaviconDB loads the favicon database from path, the file downloaded by
+// --update, or the embedded copy, in that order.
+func loadFaviconDB(path string) (map[int32]FaviconEntry, string, error) {
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read favicon database: %w", err)
+		}
+		index, err := ParseFaviconDB(data)
+		return index, path, err
+	}
+
+	if downloaded, err := GetFaviconDBPath(); err == nil {
+		if data, err := os.ReadFile(downloaded); err == nil {
+			index, err := ParseFaviconDB(data)
+			if err == nil {
+				return index, downloaded, nil
+			}
+			util.Warn("Ignoring favicon database %s: %v", downloaded, err)
+		}
+	}
+
+	index, err := ParseFaviconDB(embeddedFaviconDB)
+	return index, SourceKindEmbedded, err
+}
+
+// FaviconEngine identifies products by the Shodan-style mmh3 hash of their favicon.
+type FaviconEngine struct {
+	db       map[int32]FaviconEntry
+	evidence bool
+}
+
+// NewFaviconEngine loads the favicon database and creates the engine.
+func NewFaviconEngine(opts EngineOptions) (*FaviconEngine, error) {
+	db, source, err := loadFaviconDB(opts.FaviconDBPath)
+	if err != nil {
+		return nil, err
+	}
+	util.Debug("Using favicon database: %s (%d hashes)", source, len(db))
+	return &FaviconEngine{db: db, evidence: opts.Evidence}, nil
+}
+
+// FaviconHash hashes an icon the way Shodan does: MurmurHash3 (x86, 32-bit,
+// seed 0) of the base64 encoding with a newline every 76 characters and at the end.
+func FaviconHash(icon []byte) int32 {
+	encoded := base64.StdEncoding.EncodeToString(icon)
+	var b strings.Builder
+	b.Grow(len(encoded) + len(encoded)/76 + 1)
+	for len(encoded) > 76 {
+		b.WriteString(encoded[:76])
+		b.WriteByte('\n')
+		encoded = encoded[76:]
+	}
+	b.WriteString(encoded)
+	b.WriteByte('\n')
+	return int32(murmur3([]byte(b.String())))
+}
+
+// murmur3 is MurmurHash3_x86_32 with seed 0.
+func murmur3(data []byte) uint32 {
+	const (
+		c1 = 0xcc9e2d51
+		c2 = 0x1b873593
+	)
+	var h uint32
+	n := len(data) / 4
+	for i := 0; i < n; i++ {
+		k := binary.LittleEndian.Uint32(data[i*4:])
+		k *= c1
+		k = bits.RotateLeft32(k, 15)
+		k *= c2
+		h ^= k
+		h = bits.RotateLeft32(h, 13)
+		h = h*5 + 0xe6546b64
+	}
+
+	var k uint32
+	tail := data[n*4:]
+	switch len(tail) {
+	case 3:
+		k ^= uint32(tail[2]) << 16
+		fallthrough
+	case 2:
+		k ^= uint32(tail[1]) << 8
+		fallthrough
+	case 1:
+		k ^= uint32(tail[0])
+		k *= c1
+		k = bits.RotateLeft32(k, 15)
+		k *= c2
+		h ^= k
+	}
+
+	h ^= uint32(len(data))
+	h ^= h >> 16
+	h *= 0x85ebca6b
+	h ^= h >> 13
+	h *= 0xc2b2ae35
+	h ^= h >> 16
+	return h
+}
+
+// isIconResponse reports whether a response is an image that may be a favicon.
+func isIconResponse(headers map[string][]string, body []byte) bool {
+	ct := ""
+	for k, v := range headers {
+		if strings.EqualFold(k, "Content-Type") && len(v) > 0 {
+			ct = strings.ToLower(v[0])
+			break
+		}
+	}
+	if ct != "" {
+		return strings.HasPrefix(ct, "image/")
+	}
+	// ICO magic, for dumps without a Content-Type
+	return len(body) >= 4 && body[0] == 0 && body[1] == 0 && body[2] == 1 && body[3] == 0
+}
+
+// Detect hashes icon responses and looks them up in the database. Other
+// responses are ignored.
+func (e *FaviconEngine) Detect(headers map[string][]string, body []byte, sourceHint string) ([]model.Detection, error) {
+	if sourceHint == model.SourceHeadersOnly || len(body) == 0 || !isIconResponse(headers, body) {
+		return nil, nil
+	}
+
+	hash := FaviconHash(body)
+	entry, ok := e.db[hash]
+	if !ok {
+		return nil, nil
+	}
+
+	d := model.Detection{
+		Technology:      entry.Product,
+		Categories:      entry.Categories,
+		Source:          model.SourceFavicon,
+		Stage:           StageBody,
+		Path:            "fingerprint",
+		Evidence:        "favicon-mmh3",
+		ConfidenceScore: faviconConfidence,
+		Confidence:      model.ConfidenceBucket(faviconConfidence),
+		Timestamp:       time.Now().UTC(),
+	}
+	if e.evidence {
+		d.Path = "favicon"
+		d.Evidence = "mmh3:" + strconv.Itoa(int(hash))
+	}
+	return []model.Detection{d}, nil
+}
+
+// FollowUpURLs returns the icons of a page to fetch in online mode: the
+// <link rel=icon> targets, then /favicon.ico.
+func (e *FaviconEngine) FollowUpURLs(pageURL string, body []byte) []string {
+	base, err := url.Parse(pageURL)
+	if err != nil || base.Host == "" {
+		return nil
+	}
+
+	seen := make(map[string]struct{})
+	var urls []string
+	add := func(ref string) {
+		u, err := base.Parse(ref)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return
+		}
+		u.Fragment = ""
+		if _, dup := seen[u.String()]; dup {
+			return
+		}
+		seen[u.String()] = struct{}{}
+		urls = append(urls, u.String())
+	}
+
+	for _, link := range iconLinkRegex.FindAll(body, maxIconLinks) {
+		if m := hrefRegex.FindSubmatch(link); m != nil {
+			add(string(m[1]))
+		}
+	}
+	add("/favicon.ico")
+	return urls
+}
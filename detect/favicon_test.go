@@ -0,0 +1,116 @@
+package detect
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Abhaythakor/hyperwapp/model"
+)
+
+func TestMurmur3(t *testing.T) {
+	tests := []struct {
+		input string
+		want  uint32
+	}{
+		{"", 0},
+		{"hello", 0x248bfa47},
+		{"The quick brown fox jumps over the lazy dog", 0x2e4ff723},
+	}
+	for _, tt := range tests {
+		if got := murmur3([]byte(tt.input)); got != tt.want {
+			t.Errorf("murmur3(%q) = %#x, want %#x", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestFaviconHashEncoding(t *testing.T) {
+	// Shodan hashes the MIME-style base64 (76-char lines, trailing newline)
+	icon := []byte(strings.Repeat("\x00\x00\x01\x00icon-bytes", 20))
+	if got, want := FaviconHash(icon), int32(murmur3([]byte(mimeBase64(icon)))); got != want {
+		t.Errorf("FaviconHash() = %d, want %d", got, want)
+	}
+}
+
+// mimeBase64 mirrors Python's base64.encodebytes.
+func mimeBase64(data []byte) string {
+	var b strings.Builder
+	const chunk = 57 // 57 bytes encode to one 76-char line
+	for len(data) > 0 {
+		n := chunk
+		if len(data) < n {
+			n = len(data)
+		}
+		b.WriteString(base64.StdEncoding.EncodeToString(data[:n]))
+		b.WriteByte('\n')
+		data = data[n:]
+	}
+	return b.String()
+}
+
+func TestFaviconEngine(t *testing.T) {
+	icon := []byte("\x00\x00\x01\x00fake-appliance-icon")
+	db := fmt.Sprintf(`{"favicons": [{"hash": %d, "product": "Acme VPN", "categories": ["VPN"]}]}`, FaviconHash(icon))
+	path := filepath.Join(t.TempDir(), "favicons.json")
+	if err := os.WriteFile(path, []byte(db), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	engine, err := NewFaviconEngine(EngineOptions{FaviconDBPath: path})
+	if err != nil {
+		t.Fatalf("NewFaviconEngine() error = %v", err)
+	}
+
+	// Offline dump without a Content-Type: recognised by the ICO magic
+	detections, err := engine.Detect(nil, icon, "")
+	if err != nil || len(detections) != 1 {
+		t.Fatalf("Detect() = %v, %v, want one detection", detections, err)
+	}
+	d := detections[0]
+	if d.Technology != "Acme VPN" || d.Source != model.SourceFavicon || d.Confidence != "high" {
+		t.Errorf("Detect() = %+v", d)
+	}
+
+	html := map[string][]string{"Content-Type": {"text/html"}}
+	if detections, _ := engine.Detect(html, icon, ""); len(detections) != 0 {
+		t.Errorf("Detect() on an HTML response = %v, want none", detections)
+	}
+	png := map[string][]string{"Content-Type": {"image/png"}}
+	if detections, _ := engine.Detect(png, []byte("unknown icon"), ""); len(detections) != 0 {
+		t.Errorf("Detect() on an unknown icon = %v, want none", detections)
+	}
+}
+
+func TestEmbeddedFaviconDB(t *testing.T) {
+	db, err := ParseFaviconDB(embeddedFaviconDB)
+	if err != nil {
+		t.Fatalf("embedded favicon database: %v", err)
+	}
+	if db[116323821].Product != "Spring Boot" {
+		t.Errorf("embedded database is missing the Spring Boot hash")
+	}
+}
+
+func TestFollowUpURLs(t *testing.T) {
+	engine := &FaviconEngine{}
+	body := []byte(`<html><head>
+		<link rel="stylesheet" href="/style.css">
+		<link rel="shortcut icon" href="/static/icon.png?v=2">
+		<link href="https://cdn.example.com/fav.ico" rel="icon">
+		<link rel=icon href=data:image/png;base64,AAAA>
+	</head></html>`)
+
+	got := engine.FollowUpURLs("https://example.com/app/index.html", body)
+	want := []string{
+		"https://example.com/static/icon.png?v=2",
+		"https://cdn.example.com/fav.ico",
+		"https://example.com/favicon.ico",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FollowUpURLs() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,142 @@
+package detect
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Abhaythakor/hyperwapp/util"
+	wappalyzer "github.com/projectdiscovery/wappalyzergo"
+)
+
+const (
+	// Fingerprint source kinds, in resolution order
+	SourceKindFile       = "file"
+	SourceKindDownloaded = "downloaded"
+	SourceKindEmbedded   = "embedded"
+)
+
+// FingerprintSource describes the fingerprints file the engine was loaded from.
+type FingerprintSource struct {
+	Kind     string    // file | downloaded | embedded
+	Path     string    // Empty for embedded fingerprints
+	Count    int       // Number of technologies
+	Checksum string    // SHA-256 of the raw fingerprints data
+	ModTime  time.Time // Zero for embedded fingerprints
+}
+
+// String renders the source for --version output.
+func (s *FingerprintSource) String() string {
+	short := s.Checksum
+	if len(short) > 12 {
+		short = short[:12]
+	}
+	switch s.Kind {
+	case SourceKindEmbedded:
+		return fmt.Sprintf("embedded (wappalyzergo), %d technologies, sha256:%s", s.Count, short)
+	default:
+		return fmt.Sprintf("%s (%s), %d technologies, sha256:%s, last updated: %s",
+			s.Kind, s.Path, s.Count, short, s.ModTime.Format("2006-01-02 15:04:05"))
+	}
+}
+
+// ValidateFingerprints checks that data is a wappalyzergo fingerprints document
+// ({"apps": {"Name": {...}}}) and returns the number of technologies it defines.
+func ValidateFingerprints(data []byte) (int, error) {
+	var doc struct {
+		Apps map[string]json.RawMessage `json:"apps"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return 0, fmt.Errorf("invalid fingerprints JSON: %w", err)
+	}
+	if len(doc.Apps) == 0 {
+		return 0, fmt.Errorf(`no technologies found (expected an "apps" object)`)
+	}
+
+	for name, raw := range doc.Apps {
+		if string(raw) == "null" {
+			return 0, fmt.Errorf("technology %q: empty definition", name)
+		}
+		var fingerprint wappalyzer.Fingerprint
+		if err := json.Unmarshal(raw, &fingerprint); err != nil {
+			return 0, fmt.Errorf("technology %q: %w", name, err)
+		}
+	}
+	return len(doc.Apps), nil
+}
+
+// ResolveFingerprints picks the fingerprints to use: an explicit path (which must
+// be valid), then the file written by --update, then the embedded data.
+func ResolveFingerprints(path string) (*FingerprintSource, error) {
+	if path != "" {
+		source, err := fingerprintSourceFromFile(path, SourceKindFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load fingerprints from %s: %w", path, err)
+		}
+		return source, nil
+	}
+
+	if downloaded, err := GetFingerprintsPath(); err == nil {
+		if _, statErr := os.Stat(downloaded); statErr == nil {
+			source, err := fingerprintSourceFromFile(downloaded, SourceKindDownloaded)
+			if err == nil {
+				return source, nil
+			}
+			util.Warn("Ignoring downloaded fingerprints %s: %v", downloaded, err)
+		}
+	}
+
+	raw := []byte(wappalyzer.GetRawFingerprints())
+	count, err := ValidateFingerprints(raw)
+	if err != nil {
+		return nil, fmt.Errorf("embedded fingerprints are invalid: %w", err)
+	}
+	sum := sha256.Sum256(raw)
+	return &FingerprintSource{
+		Kind:     SourceKindEmbedded,
+		Count:    count,
+		Checksum: hex.EncodeToString(sum[:]),
+	}, nil
+}
+
+func fingerprintSourceFromFile(path, kind string) (*FingerprintSource, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	count, err := ValidateFingerprints(data)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+	return &FingerprintSource{
+		Kind:     kind,
+		Path:     path,
+		Count:    count,
+		Checksum: hex.EncodeToString(sum[:]),
+		ModTime:  info.ModTime(),
+	}, nil
+}
+
+// Data returns the raw fingerprints JSON of the source.
+func (s *FingerprintSource) Data() ([]byte, error) {
+	if s.Kind == SourceKindEmbedded {
+		return []byte(wappalyzer.GetRawFingerprints()), nil
+	}
+	return os.ReadFile(s.Path)
+}
+
+// newClient creates a wappalyzergo client for the resolved fingerprint source.
+func newClient(source *FingerprintSource) (*wappalyzer.Wappalyze, error) {
+	if source.Kind == SourceKindEmbedded {
+		return wappalyzer.New()
+	}
+	return wappalyzer.NewFromFile(source.Path, false, false)
+}
@@ -0,0 +1,63 @@
+package detect
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestValidateFingerprints(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		wantCount int
+		wantErr   bool
+	}{
+		{
+			name:      "Valid",
+			data:      `{"apps": {"Acme": {"cats": [1], "headers": {"X-Acme": ""}}, "Other": {"html": ["<acme"]}}}`,
+			wantCount: 2,
+		},
+		{name: "Not JSON", data: `not json`, wantErr: true},
+		{name: "Missing apps", data: `{"technologies": {}}`, wantErr: true},
+		{name: "Null technology", data: `{"apps": {"Acme": null}}`, wantErr: true},
+		{name: "Wrong field type", data: `{"apps": {"Acme": {"cats": "CMS"}}}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			count, err := ValidateFingerprints([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateFingerprints() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if count != tt.wantCount {
+				t.Errorf("ValidateFingerprints() = %d; want %d", count, tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestEngineFromFingerprintsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fingerprints.json")
+	data := `{"apps": {"Acme Server": {"cats": [22], "headers": {"server": "^acme(?:/([\\d.]+))?\\;version:\\1"}}}}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	engine, err := NewWappalyzerEngine(EngineOptions{FingerprintsPath: path})
+	if err != nil {
+		t.Fatalf("NewWappalyzerEngine failed: %v", err)
+	}
+	if source := engine.Source(); source.Kind != SourceKindFile || source.Count != 1 || source.Checksum == "" {
+		t.Errorf("Unexpected fingerprint source: %+v", source)
+	}
+
+	detections, _ := engine.Detect(map[string][]string{"Server": {"Acme/1.2"}}, nil, "")
+	if len(detections) != 1 || detections[0].Technology != "Acme Server" || detections[0].Version != "1.2" {
+		t.Errorf("Expected Acme Server 1.2 from custom fingerprints, got %v", detections)
+	}
+
+	if _, err := NewWappalyzerEngine(EngineOptions{FingerprintsPath: filepath.Join(t.TempDir(), "missing.json")}); err == nil {
+		t.Errorf("Expected an error for a missing fingerprints file")
+	}
+}
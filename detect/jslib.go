@@ -0,0 +1,279 @@
+package detect
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Abhaythakor/hyperwapp/model"
+)
+
+// EngineJSLib is the registry name of the JavaScript library engine.
+const EngineJSLib = "jslib"
+
+const (
+	jsContentConfidence = 100 // Banner or version assignment in the code itself
+	jsURLConfidence     = 80  // Version segment of a script URL
+)
+
+// versionPattern replaces §§version§§ in library patterns, as in retire.js.
+const versionPattern = `(\d+\.\d+(?:\.\d+)?(?:-(?:alpha|beta|rc)\.?\d*)?)`
+
+// jsLibrary describes how to recognise a JavaScript library and its version.
+type jsLibrary struct {
+	name       string
+	categories []string
+	hint       string   // Literal that must appear in the content before the patterns are tried
+	content    []string // Banners and version assignments (file contents or inline scripts)
+	aliases    []string // Package and file names used in CDN URLs
+}
+
+// jsLibraries is modelled on the retire.js repository. Names match the
+// Wappalyzer technologies so that the composite engine merges the results.
+var jsLibraries = []jsLibrary{
+	{
+		name: "jQuery", categories: []string{"JavaScript libraries"}, hint: "jquery",
+		content: []string{`/\*!? jQuery v§§version§§`, `\* jQuery JavaScript Library v§§version§§`, `[^a-z.]jquery:\s?"§§version§§"`},
+		aliases: []string{"jquery"},
+	},
+	{
+		name: "jQuery UI", categories: []string{"JavaScript libraries"}, hint: "jquery ui",
+		content: []string{`/\*!? jQuery UI - v§§version§§`},
+		aliases: []string{"jqueryui", "jquery-ui", "jquery-ui-dist"},
+	},
+	{
+		name: "jQuery Migrate", categories: []string{"JavaScript libraries"}, hint: "migrate",
+		content: []string{`/\*!? jQuery Migrate (?:- )?v§§version§§`, `migrateVersion\s?=\s?"§§version§§"`},
+		aliases: []string{"jquery-migrate"},
+	},
+	{
+		name: "Bootstrap", categories: []string{"UI frameworks"}, hint: "bootstrap",
+		content: []string{`/\*!?\s*\*?\s*Bootstrap v§§version§§`},
+		aliases: []string{"bootstrap", "twitter-bootstrap"},
+	},
+	{
+		name: "AngularJS", categories: []string{"JavaScript frameworks"}, hint: "angular",
+		content: []string{`/\*[*\s]+(?:@license )?AngularJS v§§version§§`, `errors\.angularjs\.org/§§version§§/`},
+		aliases: []string{"angular.js", "angularjs", "angular"},
+	},
+	{
+		name: "React", categories: []string{"JavaScript frameworks"}, hint: "react",
+		content: []string{`(?:/\*\*|@license)\s*\*?\s*React(?:DOM)?(?: [\w.-]+\.js)? v§§version§§`},
+		aliases: []string{"react", "react-dom"},
+	},
+	{
+		name: "Vue.js", categories: []string{"JavaScript frameworks"}, hint: "vue",
+		content: []string{`Vue\.js v§§version§§`, `Vue\.version\s?=\s?['"]§§version§§['"]`},
+		aliases: []string{"vue", "vue.js"},
+	},
+	{
+		name: "Lodash", categories: []string{"JavaScript libraries"}, hint: "lodash",
+		content: []string{`\*\s+(?:@license\s+)?(?:Lo-Dash|[Ll]odash) §§version§§`},
+		aliases: []string{"lodash", "lodash.js"},
+	},
+	{
+		name: "Underscore.js", categories: []string{"JavaScript libraries"}, hint: "underscore",
+		content: []string{`//\s+Underscore\.js §§version§§`},
+		aliases: []string{"underscore", "underscore.js"},
+	},
+	{
+		name: "Moment.js", categories: []string{"JavaScript libraries"}, hint: "moment",
+		content: []string{`//! moment\.js\s*//! version : §§version§§`},
+		aliases: []string{"moment", "moment.js"},
+	},
+	{
+		name: "Handlebars", categories: []string{"JavaScript frameworks"}, hint: "handlebars",
+		content: []string{`handlebars v§§version§§`},
+		aliases: []string{"handlebars", "handlebars.js"},
+	},
+	{
+		name: "D3", categories: []string{"JavaScript graphics"}, hint: "d3js",
+		content: []string{`// https://d3js\.org v§§version§§`},
+		aliases: []string{"d3"},
+	},
+	{
+		name: "Backbone.js", categories: []string{"JavaScript frameworks"}, hint: "backbone",
+		content: []string{`//\s+Backbone\.js §§version§§`},
+		aliases: []string{"backbone", "backbone.js"},
+	},
+	{
+		name: "Knockout.js", categories: []string{"JavaScript frameworks"}, hint: "knockout",
+		content: []string{`Knockout JavaScript library v§§version§§`},
+		aliases: []string{"knockout"},
+	},
+	{
+		name: "DOMPurify", categories: []string{"JavaScript libraries"}, hint: "dompurify",
+		content: []string{`/\*! @license DOMPurify §§version§§`},
+		aliases: []string{"dompurify"},
+	},
+	{
+		name: "Chart.js", categories: []string{"JavaScript graphics"}, hint: "chart.js",
+		content: []string{`\* Chart\.js v§§version§§`},
+		aliases: []string{"chart.js"},
+	},
+	{
+		name: "Popper", categories: []string{"Miscellaneous"}, hint: "popper",
+		content: []string{`@popperjs/core v§§version§§`, `Popper\.js v§§version§§`},
+		aliases: []string{"popper.js", "@popperjs/core", "popperjs"},
+	},
+	{
+		name: "Axios", categories: []string{"JavaScript libraries"}, hint: "axios",
+		content: []string{`/\*!? Axios v§§version§§`},
+		aliases: []string{"axios"},
+	},
+	{
+		name: "Select2", categories: []string{"JavaScript libraries"}, hint: "select2",
+		content: []string{`/\*!? Select2 §§version§§`},
+		aliases: []string{"select2"},
+	},
+}
+
+var (
+	scriptSrcAttrRegex = regexp.MustCompile(`(?i)<script[^>]+src=["']?([^"'\s>]+)`)
+	// CDN layouts: cdnjs/googleapis (/libs/<name>/<version>/), jsdelivr/unpkg (<name>@<version>)
+	cdnLibRegex = regexp.MustCompile(`(?i)/(?:ajax/)?libs/([\w.-]+)/` + versionPattern + `/`)
+	npmCDNRegex = regexp.MustCompile(`(?i)(?:/npm/|unpkg\.com/)((?:@[\w.-]+/)?[\w.-]+)@` + versionPattern)
+	// File names: jquery-3.5.1.min.js, jquery.3.5.1.js, code.jquery.com/ui/1.13.2/
+	fileLibRegex  = regexp.MustCompile(`(?i)/([a-z][\w]*(?:[.-][a-z][\w]*)*)[.-]v?` + versionPattern + `(?:\.slim|\.min|\.prod|\.production)*\.js`)
+	jqueryUIRegex = regexp.MustCompile(`(?i)code\.jquery\.com/ui/` + versionPattern + `/`)
+)
+
+// compiledJSLibrary holds the compiled patterns of a jsLibrary.
+type compiledJSLibrary struct {
+	*jsLibrary
+	hint    []byte
+	content []*regexp.Regexp
+}
+
+// JSLibEngine detects JavaScript libraries and their versions from version
+// banners and assignments in script contents and from script URLs. It reads the
+// raw body, so scripts cut by pruneBody are still seen.
+type JSLibEngine struct {
+	libraries []compiledJSLibrary
+	aliases   map[string]*jsLibrary // lowercase package/file name -> library
+	evidence  bool
+}
+
+func init() {
+	RegisterEngine(EngineJSLib, func(opts EngineOptions) (Engine, error) {
+		return NewJSLibEngine(opts), nil
+	})
+}
+
+// NewJSLibEngine compiles the library patterns and creates the engine.
+func NewJSLibEngine(opts EngineOptions) *JSLibEngine {
+	e := &JSLibEngine{
+		aliases:  make(map[string]*jsLibrary),
+		evidence: opts.Evidence,
+	}
+	for i := range jsLibraries {
+		lib := &jsLibraries[i]
+		compiled := compiledJSLibrary{jsLibrary: lib, hint: []byte(lib.hint)}
+		for _, pattern := range lib.content {
+			compiled.content = append(compiled.content, regexp.MustCompile(strings.ReplaceAll(pattern, "§§version§§", versionPattern)))
+		}
+		e.libraries = append(e.libraries, compiled)
+		for _, alias := range lib.aliases {
+			e.aliases[alias] = lib
+		}
+	}
+	return e
+}
+
+// jsMatch is a library found by the engine.
+type jsMatch struct {
+	lib        *jsLibrary
+	version    string
+	path       string // js-content | script-src
+	matched    string
+	confidence int
+}
+
+// Detect scans script files and HTML pages for library versions.
+func (e *JSLibEngine) Detect(headers map[string][]string, body []byte, sourceHint string) ([]model.Detection, error) {
+	if sourceHint == model.SourceHeadersOnly || len(body) == 0 || isBinaryResponse(headers, body) {
+		return nil, nil
+	}
+
+	var matches []jsMatch
+	matches = append(matches, e.matchContent(body)...)
+	matches = append(matches, e.matchScriptURLs(body)...)
+	if len(matches) == 0 {
+		return nil, nil
+	}
+
+	now := time.Now().UTC()
+	index := make(map[string]int)
+	var detections []model.Detection
+	for _, m := range matches {
+		// Content matches come first, so they win over URL matches
+		if _, seen := index[m.lib.name]; seen {
+			continue
+		}
+		index[m.lib.name] = len(detections)
+
+		d := model.Detection{
+			Technology:      m.lib.name,
+			Version:         m.version,
+			Categories:      m.lib.categories,
+			Source:          model.SourceJSLib,
+			Stage:           StageBody,
+			Path:            "fingerprint",
+			Evidence:        EngineJSLib,
+			ConfidenceScore: m.confidence,
+			Confidence:      model.ConfidenceBucket(m.confidence),
+			Timestamp:       now,
+		}
+		if e.evidence {
+			d.Path = m.path
+			d.Evidence = truncateEvidence(m.matched)
+		}
+		detections = append(detections, d)
+	}
+	return detections, nil
+}
+
+// matchContent looks for version banners and assignments in the body.
+func (e *JSLibEngine) matchContent(body []byte) []jsMatch {
+	lower := bytes.ToLower(body)
+	var matches []jsMatch
+	for _, lib := range e.libraries {
+		if !bytes.Contains(lower, lib.hint) {
+			continue
+		}
+		for _, re := range lib.content {
+			if m := re.FindSubmatch(body); m != nil {
+				matches = append(matches, jsMatch{lib.jsLibrary, string(m[1]), "js-content", string(m[0]), jsContentConfidence})
+				break
+			}
+		}
+	}
+	return matches
+}
+
+// matchScriptURLs looks for library versions in the src of <script> tags.
+func (e *JSLibEngine) matchScriptURLs(body []byte) []jsMatch {
+	var matches []jsMatch
+	for _, src := range scriptSrcAttrRegex.FindAllSubmatch(body, -1) {
+		if lib, version := e.matchURL(string(src[1])); lib != nil {
+			matches = append(matches, jsMatch{lib, version, "script-src", string(src[1]), jsURLConfidence})
+		}
+	}
+	return matches
+}
+
+// matchURL returns the library and version named by a script URL.
+func (e *JSLibEngine) matchURL(src string) (*jsLibrary, string) {
+	if m := jqueryUIRegex.FindStringSubmatch(src); m != nil {
+		return e.aliases["jquery-ui"], m[1]
+	}
+	for _, re := range []*regexp.Regexp{npmCDNRegex, cdnLibRegex, fileLibRegex} {
+		if m := re.FindStringSubmatch(src); m != nil {
+			if lib, ok := e.aliases[strings.ToLower(m[1])]; ok {
+				return lib, m[2]
+			}
+		}
+	}
+	return nil, ""
+}
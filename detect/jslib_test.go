@@ -0,0 +1,121 @@
+package detect
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Abhaythakor/hyperwapp/model"
+)
+
+func TestJSLibEngine(t *testing.T) {
+	// A bundled library far larger than the 5KB pruneBody keeps of a script
+	bigScript := "/*! jQuery v3.5.1 | (c) JS Foundation and other contributors | jquery.org/license */\n" + strings.Repeat("!function(e){};", 1000)
+
+	tests := []struct {
+		name        string
+		headers     map[string][]string
+		body        string
+		wantTech    string
+		wantVersion string
+		wantScore   int
+	}{
+		{
+			name:        "Banner in script file",
+			headers:     map[string][]string{"Content-Type": {"application/javascript"}},
+			body:        bigScript,
+			wantTech:    "jQuery",
+			wantVersion: "3.5.1",
+			wantScore:   jsContentConfidence,
+		},
+		{
+			name:        "Banner in inline script",
+			body:        "<html><script>" + bigScript + "</script></html>",
+			wantTech:    "jQuery",
+			wantVersion: "3.5.1",
+			wantScore:   jsContentConfidence,
+		},
+		{
+			name:        "Multi-line banner",
+			body:        "/*!\n * Bootstrap v5.3.2 (https://getbootstrap.com/)\n */",
+			wantTech:    "Bootstrap",
+			wantVersion: "5.3.2",
+			wantScore:   jsContentConfidence,
+		},
+		{
+			name:        "Version assignment",
+			body:        `(function(){ Vue.version = '2.6.14'; })()`,
+			wantTech:    "Vue.js",
+			wantVersion: "2.6.14",
+			wantScore:   jsContentConfidence,
+		},
+		{
+			name:        "Minified AngularJS error URL",
+			body:        `throw Error("http://errors.angularjs.org/1.8.2/"+a)`,
+			wantTech:    "AngularJS",
+			wantVersion: "1.8.2",
+			wantScore:   jsContentConfidence,
+		},
+		{
+			name:        "cdnjs URL",
+			body:        `<script src="https://cdnjs.cloudflare.com/ajax/libs/lodash.js/4.17.21/lodash.min.js"></script>`,
+			wantTech:    "Lodash",
+			wantVersion: "4.17.21",
+			wantScore:   jsURLConfidence,
+		},
+		{
+			name:        "jsDelivr scoped package",
+			body:        `<script src="https://cdn.jsdelivr.net/npm/@popperjs/core@2.11.8/dist/umd/popper.min.js"></script>`,
+			wantTech:    "Popper",
+			wantVersion: "2.11.8",
+			wantScore:   jsURLConfidence,
+		},
+		{
+			name:        "File name",
+			body:        `<script type="text/javascript" src="/static/js/jquery-1.12.4-rc1.min.js"></script>`,
+			wantTech:    "jQuery",
+			wantVersion: "1.12.4-rc1",
+			wantScore:   jsURLConfidence,
+		},
+		{
+			name:        "jQuery UI CDN",
+			body:        `<script src="https://code.jquery.com/ui/1.13.2/jquery-ui.min.js"></script>`,
+			wantTech:    "jQuery UI",
+			wantVersion: "1.13.2",
+			wantScore:   jsURLConfidence,
+		},
+	}
+
+	engine := NewJSLibEngine(EngineOptions{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detections, err := engine.Detect(tt.headers, []byte(tt.body), "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, d := range detections {
+				if d.Technology == tt.wantTech {
+					if d.Version != tt.wantVersion || d.ConfidenceScore != tt.wantScore || d.Source != model.SourceJSLib {
+						t.Errorf("%s = %q (score %d, source %s), want %q (score %d)", d.Technology, d.Version, d.ConfidenceScore, d.Source, tt.wantVersion, tt.wantScore)
+					}
+					return
+				}
+			}
+			t.Errorf("Detect() = %v, want %s", detections, tt.wantTech)
+		})
+	}
+}
+
+func TestJSLibEngineIgnores(t *testing.T) {
+	engine := NewJSLibEngine(EngineOptions{})
+	for _, body := range []string{
+		`<script src="/js/app-2.1.0.min.js"></script>`,
+		`<p>We love jQuery v3 and React</p>`,
+	} {
+		if detections, _ := engine.Detect(nil, []byte(body), ""); len(detections) != 0 {
+			t.Errorf("Detect(%q) = %v, want none", body, detections)
+		}
+	}
+	if detections, _ := engine.Detect(nil, []byte("/*! jQuery v3.5.1 */"), model.SourceHeadersOnly); len(detections) != 0 {
+		t.Errorf("Detect() in headers-only mode = %v, want none", detections)
+	}
+}
@@ -0,0 +1,367 @@
+package detect
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Abhaythakor/hyperwapp/util"
+	wappalyzer "github.com/projectdiscovery/wappalyzergo"
+	"gopkg.in/yaml.v3"
+)
+
+// Pack is a user-defined set of fingerprints loaded from a single file.
+type Pack struct {
+	Name string // File name without extension
+	Path string
+	Apps map[string]*wappalyzer.Fingerprint
+}
+
+// LintIssue is a problem found by LintPacks.
+type LintIssue struct {
+	Severity   string // error | warning
+	Pack       string
+	Technology string
+	Message    string
+}
+
+func (i LintIssue) String() string {
+	if i.Technology == "" {
+		return fmt.Sprintf("[%s] %s: %s", i.Severity, i.Pack, i.Message)
+	}
+	return fmt.Sprintf("[%s] %s: %s: %s", i.Severity, i.Pack, i.Technology, i.Message)
+}
+
+// stringList accepts either a single string or a list of strings, as Wappalyzer does.
+type stringList []string
+
+func (l *stringList) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*l = stringList{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("expected a string or a list of strings")
+	}
+	*l = list
+	return nil
+}
+
+// packTechnology is a technology definition in Wappalyzer's format, which is
+// looser than the normalized format wappalyzergo ships with.
+type packTechnology struct {
+	Cats        []int                             `json:"cats"`
+	CSS         stringList                        `json:"css"`
+	Cookies     map[string]string                 `json:"cookies"`
+	Dom         map[string]map[string]interface{} `json:"dom"`
+	JS          map[string]string                 `json:"js"`
+	Headers     map[string]string                 `json:"headers"`
+	HTML        stringList                        `json:"html"`
+	Scripts     stringList                        `json:"scripts"`
+	ScriptSrc   stringList                        `json:"scriptSrc"`
+	Meta        map[string]stringList             `json:"meta"`
+	Implies     stringList                        `json:"implies"`
+	Description string                            `json:"description"`
+	Website     string                            `json:"website"`
+	CPE         string                            `json:"cpe"`
+	Icon        string                            `json:"icon"`
+}
+
+// normalize converts the definition to wappalyzergo's format. Header, cookie and
+// meta names are lowercased because wappalyzergo matches them lowercased.
+func (t *packTechnology) normalize() *wappalyzer.Fingerprint {
+	lowerKeys := func(m map[string]string) map[string]string {
+		out := make(map[string]string, len(m))
+		for k, v := range m {
+			out[strings.ToLower(k)] = v
+		}
+		return out
+	}
+
+	meta := make(map[string][]string, len(t.Meta))
+	for k, v := range t.Meta {
+		meta[strings.ToLower(k)] = v
+	}
+
+	return &wappalyzer.Fingerprint{
+		Cats:        t.Cats,
+		CSS:         t.CSS,
+		Cookies:     lowerKeys(t.Cookies),
+		Dom:         t.Dom,
+		JS:          t.JS,
+		Headers:     lowerKeys(t.Headers),
+		HTML:        t.HTML,
+		Script:      t.Scripts,
+		ScriptSrc:   t.ScriptSrc,
+		Meta:        meta,
+		Implies:     t.Implies,
+		Description: t.Description,
+		Website:     t.Website,
+		CPE:         t.CPE,
+		Icon:        t.Icon,
+	}
+}
+
+// isPackFile reports whether a file name looks like a fingerprint pack.
+func isPackFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json", ".yaml", ".yml":
+		return true
+	}
+	return false
+}
+
+// LoadPacks loads every fingerprint pack (*.json, *.yaml, *.yml) in dir, sorted by name.
+func LoadPacks(dir string) ([]*Pack, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fingerprint packs directory: %w", err)
+	}
+
+	var packs []*Pack
+	for _, entry := range entries {
+		if entry.IsDir() || !isPackFile(entry.Name()) {
+			continue
+		}
+		pack, err := LoadPack(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		packs = append(packs, pack)
+	}
+
+	sort.Slice(packs, func(i, j int) bool {
+		return packs[i].Name < packs[j].Name
+	})
+	return packs, nil
+}
+
+// LoadPack loads a single fingerprint pack. Both the wappalyzergo layout
+// ({"apps": {"Name": {...}}}) and Wappalyzer's flat layout ({"Name": {...}}) are
+// accepted, in JSON or YAML.
+func LoadPack(path string) (*Pack, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fingerprint pack %s: %w", path, err)
+	}
+
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == ".yaml" || ext == ".yml" {
+		// Re-encode as JSON so both formats share the same decoding rules
+		var doc interface{}
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("invalid YAML in fingerprint pack %s: %w", path, err)
+		}
+		data, err = json.Marshal(doc)
+		if err != nil {
+			return nil, fmt.Errorf("unsupported YAML in fingerprint pack %s: %w", path, err)
+		}
+	}
+
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid fingerprint pack %s: %w", path, err)
+	}
+	if apps, ok := doc["apps"]; ok && len(doc) == 1 {
+		doc = nil
+		if err := json.Unmarshal(apps, &doc); err != nil {
+			return nil, fmt.Errorf("invalid fingerprint pack %s: %w", path, err)
+		}
+	}
+
+	pack := &Pack{
+		Name: strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+		Path: path,
+		Apps: make(map[string]*wappalyzer.Fingerprint, len(doc)),
+	}
+	for name, raw := range doc {
+		var tech packTechnology
+		if err := json.Unmarshal(raw, &tech); err != nil {
+			return nil, fmt.Errorf("fingerprint pack %s: technology %q: %w", path, name, err)
+		}
+		pack.Apps[name] = tech.normalize()
+	}
+	if len(pack.Apps) == 0 {
+		return nil, fmt.Errorf("fingerprint pack %s defines no technologies", path)
+	}
+	return pack, nil
+}
+
+// LoadFingerprints decodes the fingerprints of a source.
+func LoadFingerprints(source *FingerprintSource) (*wappalyzer.Fingerprints, error) {
+	data, err := source.Data()
+	if err != nil {
+		return nil, err
+	}
+	var fingerprints wappalyzer.Fingerprints
+	if err := json.Unmarshal(data, &fingerprints); err != nil {
+		return nil, fmt.Errorf("failed to decode fingerprints: %w", err)
+	}
+	return &fingerprints, nil
+}
+
+// mergePacks adds the pack technologies to the built-in fingerprints and returns
+// the pack each technology came from. Packs override built-in technologies of
+// the same name, and later packs override earlier ones.
+func mergePacks(fingerprints *wappalyzer.Fingerprints, packs []*Pack) map[string]string {
+	packOf := make(map[string]string)
+	for _, pack := range packs {
+		for _, name := range sortedNames(pack.Apps) {
+			if previous, ok := packOf[name]; ok {
+				util.Warn("Fingerprint pack %s redefines %q from pack %s", pack.Name, name, previous)
+			} else if _, ok := fingerprints.Apps[name]; ok {
+				util.Debug("Fingerprint pack %s overrides built-in %q", pack.Name, name)
+			}
+			fingerprints.Apps[name] = pack.Apps[name]
+			packOf[name] = pack.Name
+		}
+	}
+	return packOf
+}
+
+// newClientWithPacks creates a wappalyzergo client from the resolved fingerprints
+// merged with the given packs. wappalyzergo only loads fingerprints from a file,
+// so the merged set is written to a temporary file first.
+func newClientWithPacks(source *FingerprintSource, packs []*Pack) (*wappalyzer.Wappalyze, map[string]string, error) {
+	fingerprints, err := LoadFingerprints(source)
+	if err != nil {
+		return nil, nil, err
+	}
+	packOf := mergePacks(fingerprints, packs)
+
+	tmp, err := os.CreateTemp("", "HyperWapp-fingerprints-*.json")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create temporary fingerprints file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	err = json.NewEncoder(tmp).Encode(fingerprints)
+	tmp.Close()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to write merged fingerprints: %w", err)
+	}
+
+	client, err := wappalyzer.NewFromFile(tmp.Name(), false, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	return client, packOf, nil
+}
+
+const builtinOwner = "built-in fingerprints"
+
+// LintPacks checks the packs in dir for invalid patterns, duplicate technology
+// names (between packs or with the built-in set), unknown categories and
+// "implies" references to technologies that do not exist.
+func LintPacks(dir string, builtin *wappalyzer.Fingerprints) ([]LintIssue, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fingerprint packs directory: %w", err)
+	}
+
+	var issues []LintIssue
+	var packs []*Pack
+	for _, entry := range entries {
+		if entry.IsDir() || !isPackFile(entry.Name()) {
+			continue
+		}
+		pack, err := LoadPack(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			issues = append(issues, LintIssue{Severity: "error", Pack: entry.Name(), Message: err.Error()})
+			continue
+		}
+		packs = append(packs, pack)
+	}
+
+	known := make(map[string]string) // technology -> defining pack
+	for name := range builtin.Apps {
+		known[name] = builtinOwner
+	}
+
+	categories := wappalyzer.GetCategoriesMapping()
+	for _, pack := range packs {
+		for _, name := range sortedNames(pack.Apps) {
+			if owner, ok := known[name]; ok && owner != pack.Name {
+				severity := "error"
+				if owner == builtinOwner {
+					severity = "warning" // Overriding a built-in technology is allowed
+				}
+				issues = append(issues, LintIssue{severity, pack.Name, name, "duplicate technology name (also defined in " + owner + ")"})
+			}
+			known[name] = pack.Name
+
+			fingerprint := pack.Apps[name]
+			for _, problem := range lintPatterns(fingerprint) {
+				issues = append(issues, LintIssue{"error", pack.Name, name, problem})
+			}
+			for _, cat := range fingerprint.Cats {
+				if _, ok := categories[cat]; !ok {
+					issues = append(issues, LintIssue{"warning", pack.Name, name, fmt.Sprintf("unknown category id %d", cat)})
+				}
+			}
+		}
+	}
+
+	for _, pack := range packs {
+		for _, name := range sortedNames(pack.Apps) {
+			for _, implied := range pack.Apps[name].Implies {
+				target, _, _ := strings.Cut(implied, `\;`)
+				if _, ok := known[target]; !ok {
+					issues = append(issues, LintIssue{"error", pack.Name, name, fmt.Sprintf("implies unknown technology %q", target)})
+				}
+			}
+		}
+	}
+	return issues, nil
+}
+
+// lintPatterns compiles every pattern of a fingerprint the way wappalyzergo does
+// and returns a description of each one that fails.
+func lintPatterns(fingerprint *wappalyzer.Fingerprint) []string {
+	var problems []string
+	check := func(field, pattern string) {
+		if _, err := compileEvidencePattern(pattern); err != nil {
+			problems = append(problems, fmt.Sprintf("invalid %s pattern %q: %v", field, pattern, err))
+		}
+	}
+
+	for name, pattern := range fingerprint.Headers {
+		check("headers."+name, pattern)
+	}
+	for name, pattern := range fingerprint.Cookies {
+		check("cookies."+name, pattern)
+	}
+	for name, pattern := range fingerprint.JS {
+		check("js."+name, pattern)
+	}
+	for name, patterns := range fingerprint.Meta {
+		for _, pattern := range patterns {
+			check("meta."+name, pattern)
+		}
+	}
+	for _, pattern := range fingerprint.HTML {
+		check("html", pattern)
+	}
+	for _, pattern := range fingerprint.Script {
+		check("scripts", pattern)
+	}
+	for _, pattern := range fingerprint.ScriptSrc {
+		check("scriptSrc", pattern)
+	}
+	sort.Strings(problems)
+	return problems
+}
+
+func sortedNames(apps map[string]*wappalyzer.Fingerprint) []string {
+	names := make([]string, 0, len(apps))
+	for name := range apps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
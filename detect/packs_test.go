@@ -0,0 +1,129 @@
+package detect
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Abhaythakor/hyperwapp/model"
+)
+
+func writePack(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadPack(t *testing.T) {
+	dir := t.TempDir()
+	writePack(t, dir, "flat.json", `{"Acme Portal": {"cats": [1], "headers": {"X-Acme": "portal/([\\d.]+)\\;version:\\1"}, "html": "<acme-portal", "implies": "PHP"}}`)
+	writePack(t, dir, "wrapped.yaml", `
+apps:
+  Acme CDN:
+    cats: [31]
+    headers:
+      X-Served-By: acme-cdn
+    scriptSrc:
+      - cdn\.acme\.test
+`)
+	writePack(t, dir, "notes.txt", `ignored`)
+
+	packs, err := LoadPacks(dir)
+	if err != nil {
+		t.Fatalf("LoadPacks() error = %v", err)
+	}
+	if len(packs) != 2 || packs[0].Name != "flat" || packs[1].Name != "wrapped" {
+		t.Fatalf("LoadPacks() = %v, want packs flat and wrapped", packs)
+	}
+
+	portal := packs[0].Apps["Acme Portal"]
+	if portal == nil || portal.Headers["x-acme"] == "" || len(portal.HTML) != 1 || len(portal.Implies) != 1 {
+		t.Errorf("flat pack not normalized: %+v", portal)
+	}
+	cdn := packs[1].Apps["Acme CDN"]
+	if cdn == nil || cdn.Headers["x-served-by"] != "acme-cdn" || len(cdn.ScriptSrc) != 1 {
+		t.Errorf("YAML pack not normalized: %+v", cdn)
+	}
+
+	writePack(t, dir, "broken.json", `{"Bad": {"html": 42}}`)
+	if _, err := LoadPacks(dir); err == nil {
+		t.Error("LoadPacks() with an invalid pack should fail")
+	}
+}
+
+func TestEngineWithPacks(t *testing.T) {
+	dir := t.TempDir()
+	writePack(t, dir, "internal.json", `{"Acme Portal": {"cats": [1], "headers": {"X-Acme": "portal/([\\d.]+)\\;version:\\1"}}}`)
+
+	engine, err := NewWappalyzerEngine(EngineOptions{PacksDir: dir})
+	if err != nil {
+		t.Fatalf("NewWappalyzerEngine() error = %v", err)
+	}
+
+	headers := map[string][]string{
+		"X-Acme": {"portal/2.1"},
+		"Server": {"nginx"},
+	}
+	detections, err := engine.Detect(headers, nil, model.SourceHeadersOnly)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	packs := make(map[string]string)
+	for _, d := range detections {
+		packs[d.Technology] = d.Pack
+		if d.Technology == "Acme Portal" && d.Version != "2.1" {
+			t.Errorf("Acme Portal version = %q, want 2.1", d.Version)
+		}
+	}
+	if packs["Acme Portal"] != "internal" {
+		t.Errorf("Acme Portal pack = %q, want internal", packs["Acme Portal"])
+	}
+	if packs["Nginx"] != model.PackBuiltin {
+		t.Errorf("Nginx pack = %q, want %s (built-in fingerprints should still load)", packs["Nginx"], model.PackBuiltin)
+	}
+}
+
+func TestLintPacks(t *testing.T) {
+	dir := t.TempDir()
+	writePack(t, dir, "a.json", `{
+		"Acme Portal": {"cats": [1], "html": "<acme-(portal"},
+		"Nginx": {"headers": {"Server": "nginx"}},
+		"Acme API": {"implies": ["Acme Portal", "Missing Tech\\;confidence:50"]}
+	}`)
+	writePack(t, dir, "b.yaml", "Acme Portal:\n  cats: [9999]\n")
+
+	builtin, err := LoadFingerprints(&FingerprintSource{Kind: SourceKindEmbedded})
+	if err != nil {
+		t.Fatal(err)
+	}
+	issues, err := LintPacks(dir, builtin)
+	if err != nil {
+		t.Fatalf("LintPacks() error = %v", err)
+	}
+
+	want := []struct{ severity, tech, message string }{
+		{"error", "Acme Portal", "invalid html pattern"},
+		{"warning", "Nginx", "duplicate technology name (also defined in built-in fingerprints)"},
+		{"error", "Acme API", `implies unknown technology "Missing Tech"`},
+		{"error", "Acme Portal", "duplicate technology name (also defined in a)"},
+		{"warning", "Acme Portal", "unknown category id 9999"},
+	}
+	for _, w := range want {
+		found := false
+		for _, issue := range issues {
+			if issue.Severity == w.severity && issue.Technology == w.tech && strings.Contains(issue.Message, w.message) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("missing %s for %s: %q in %v", w.severity, w.tech, w.message, issues)
+		}
+	}
+	if len(issues) != len(want) {
+		t.Errorf("LintPacks() returned %d issues, want %d: %v", len(issues), len(want), issues)
+	}
+}
@@ -0,0 +1,283 @@
+package detect
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Abhaythakor/hyperwapp/model"
+	"github.com/Abhaythakor/hyperwapp/util"
+)
+
+// EngineWappalyzer is the registry name of the Wappalyzer engine.
+const EngineWappalyzer = "wappalyzer"
+
+// EngineFactory creates an engine from the shared engine options.
+type EngineFactory func(opts EngineOptions) (Engine, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]EngineFactory)
+)
+
+func init() {
+	RegisterEngine(EngineWappalyzer, func(opts EngineOptions) (Engine, error) {
+		engine, err := NewWappalyzerEngine(opts)
+		if err != nil {
+			return nil, err
+		}
+		engine.SetEvidenceMode(opts.Evidence)
+		util.Debug("Using fingerprints: %s", engine.Source())
+		return engine, nil
+	})
+}
+
+// RegisterEngine makes an engine available to NewEngine under name.
+// It panics if the name is already registered.
+func RegisterEngine(name string, factory EngineFactory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, exists := registry[name]; exists {
+		panic("detect: engine registered twice: " + name)
+	}
+	registry[name] = factory
+}
+
+// EngineNames returns the names of all registered engines, sorted.
+func EngineNames() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewEngine creates a composite of the named engines, in the given order.
+func NewEngine(names []string, opts EngineOptions) (*CompositeEngine, error) {
+	if len(names) == 0 {
+		names = []string{EngineWappalyzer}
+	}
+
+	composite := &CompositeEngine{}
+	seen := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, dup := seen[name]; dup || name == "" {
+			continue
+		}
+		seen[name] = struct{}{}
+
+		registryMu.RLock()
+		factory, ok := registry[name]
+		registryMu.RUnlock()
+		if !ok {
+			return nil, fmt.Errorf("unknown engine %q (available: %s)", name, strings.Join(EngineNames(), ", "))
+		}
+
+		engine, err := factory(opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize %s engine: %w", name, err)
+		}
+		composite.names = append(composite.names, name)
+		composite.engines = append(composite.engines, engine)
+	}
+	return composite, nil
+}
+
+// FollowUpEngine is implemented by engines that need more resources of an online
+// target than the page itself, such as its favicon. The caller fetches each URL
+// and passes the response to the engine's Detect.
+type FollowUpEngine interface {
+	Engine
+	FollowUpURLs(pageURL string, body []byte) []string
+}
+
+// TargetEngine is implemented by engines that detect technologies from the target
+// itself (its TLS certificate or DNS records) rather than from a response.
+// DetectTarget may return detections along with an error when the lookup
+// failed part way.
+type TargetEngine interface {
+	Engine
+	DetectTarget(target model.Target) ([]model.Detection, error)
+}
+
+// FollowUp is a resource to fetch for an engine of the composite.
+type FollowUp struct {
+	URL    string
+	Name   string // Registry name of Engine
+	Engine Engine
+}
+
+// DefaultFollowUpCacheSize is the number of follow-up results kept in memory.
+const DefaultFollowUpCacheSize = 10000
+
+// followUpEntry is the cached result of a follow-up. once makes concurrent
+// targets needing the same resource share a single fetch.
+type followUpEntry struct {
+	once       sync.Once
+	detections []model.Detection
+}
+
+// FollowUpCache keeps the detections of the most recently fetched follow-ups,
+// keyed by engine and resolved URL, so a resource shared by many targets (the
+// /favicon.ico of a host) is fetched once per scan.
+type FollowUpCache struct {
+	entries *lru[string, *followUpEntry]
+}
+
+// NewFollowUpCache creates a cache holding at most capacity follow-ups.
+func NewFollowUpCache(capacity int) *FollowUpCache {
+	if capacity <= 0 {
+		capacity = DefaultFollowUpCacheSize
+	}
+	return &FollowUpCache{entries: newLRU[string, *followUpEntry](capacity)}
+}
+
+// Detect returns the detections of a follow-up, calling fetch only the first
+// time it is seen. Failed fetches are cached too (fetch returns nil).
+func (c *FollowUpCache) Detect(f FollowUp, fetch func() []model.Detection) []model.Detection {
+	key := f.Name + " " + f.URL
+	entry, ok := c.entries.get(key)
+	if !ok {
+		entry = c.entries.add(key, &followUpEntry{})
+	}
+	entry.once.Do(func() {
+		entry.detections = fetch()
+	})
+	return slices.Clone(entry.detections)
+}
+
+// CompositeEngine runs several engines on the same response concurrently and
+// merges their detections.
+type CompositeEngine struct {
+	names   []string
+	engines []Engine
+}
+
+// Names returns the names of the engines in the composite.
+func (c *CompositeEngine) Names() []string {
+	return c.names
+}
+
+// Lookup returns the engine registered under name, or nil if it is not part of the composite.
+func (c *CompositeEngine) Lookup(name string) Engine {
+	for i, n := range c.names {
+		if n == name {
+			return c.engines[i]
+		}
+	}
+	return nil
+}
+
+// FollowUps returns the resources the engines want fetched for a page.
+func (c *CompositeEngine) FollowUps(pageURL string, body []byte) []FollowUp {
+	var followUps []FollowUp
+	for i, engine := range c.engines {
+		if f, ok := engine.(FollowUpEngine); ok {
+			for _, u := range f.FollowUpURLs(pageURL, body) {
+				followUps = append(followUps, FollowUp{URL: u, Name: c.names[i], Engine: engine})
+			}
+		}
+	}
+	return followUps
+}
+
+// DetectTarget runs the engines that look at target metadata (see TargetEngine).
+func (c *CompositeEngine) DetectTarget(target model.Target) []model.Detection {
+	var results [][]model.Detection
+	for i, engine := range c.engines {
+		if t, ok := engine.(TargetEngine); ok {
+			detections, err := t.DetectTarget(target)
+			if err != nil {
+				util.Debug("Engine %s failed for %s: %v", c.names[i], target.URL, err)
+			}
+			results = append(results, detections) // Partial results are kept
+		}
+	}
+	return MergeDetections(results...)
+}
+
+// Detect runs every engine and merges the results. A failing engine does not
+// discard the detections of the others; an error is only returned if all fail.
+func (c *CompositeEngine) Detect(headers map[string][]string, body []byte, sourceHint string) ([]model.Detection, error) {
+	if len(c.engines) == 1 {
+		return c.detect(0, headers, body, sourceHint)
+	}
+
+	results := make([][]model.Detection, len(c.engines))
+	errs := make([]error, len(c.engines))
+	var wg sync.WaitGroup
+	for i := range c.engines {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = c.detect(i, headers, body, sourceHint)
+		}(i)
+	}
+	wg.Wait()
+
+	var firstErr error
+	failed := 0
+	for i, err := range errs {
+		if err != nil {
+			failed++
+			util.Debug("Engine %s failed: %v", c.names[i], err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	if failed == len(c.engines) {
+		return nil, firstErr
+	}
+	return MergeDetections(results...), nil
+}
+
+// detect runs a single engine and tags detections it left without a source with the engine name.
+func (c *CompositeEngine) detect(i int, headers map[string][]string, body []byte, sourceHint string) ([]model.Detection, error) {
+	detections, err := c.engines[i].Detect(headers, body, sourceHint)
+	for j := range detections {
+		if detections[j].Source == "" {
+			detections[j].Source = c.names[i]
+		}
+	}
+	return detections, err
+}
+
+// MergeDetections de-duplicates detections by technology name (case-insensitive).
+// The detection with the highest confidence wins, earlier engines winning ties;
+// a missing version or categories are filled in from the others.
+func MergeDetections(results ...[]model.Detection) []model.Detection {
+	var merged []model.Detection
+	index := make(map[string]int)
+	for _, detections := range results {
+		for _, d := range detections {
+			key := strings.ToLower(d.Technology)
+			i, ok := index[key]
+			if !ok {
+				index[key] = len(merged)
+				merged = append(merged, d)
+				continue
+			}
+
+			existing := &merged[i]
+			if d.ConfidenceScore > existing.ConfidenceScore {
+				d, *existing = *existing, d
+			}
+			if existing.Version == "" {
+				existing.Version = d.Version
+			}
+			if len(existing.Categories) == 0 {
+				existing.Categories = d.Categories
+			}
+		}
+	}
+	return merged
+}
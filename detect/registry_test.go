@@ -0,0 +1,123 @@
+package detect
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Abhaythakor/hyperwapp/model"
+)
+
+// staticEngine returns fixed detections, for exercising the composite engine.
+type staticEngine struct {
+	detections []model.Detection
+	err        error
+}
+
+func (s *staticEngine) Detect(headers map[string][]string, body []byte, sourceHint string) ([]model.Detection, error) {
+	out := make([]model.Detection, len(s.detections))
+	copy(out, s.detections)
+	return out, s.err
+}
+
+func init() {
+	RegisterEngine("test-static", func(opts EngineOptions) (Engine, error) {
+		return &staticEngine{detections: []model.Detection{
+			{Technology: "nginx", ConfidenceScore: 50},
+			{Technology: "Acme", Version: "1.0", ConfidenceScore: 100},
+		}}, nil
+	})
+	RegisterEngine("test-failing", func(opts EngineOptions) (Engine, error) {
+		return &staticEngine{err: errors.New("boom")}, nil
+	})
+}
+
+func TestCompositeEngine(t *testing.T) {
+	engine, err := NewEngine([]string{"wappalyzer", "test-static", "test-failing", "wappalyzer"}, EngineOptions{})
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+	if got := engine.Names(); len(got) != 3 {
+		t.Fatalf("Names() = %v, want 3 unique engines", got)
+	}
+
+	headers := map[string][]string{"Server": {"nginx/1.25.3"}}
+	detections, err := engine.Detect(headers, nil, model.SourceHeadersOnly)
+	if err != nil {
+		t.Fatalf("Detect() error = %v (a single failing engine should not fail the scan)", err)
+	}
+
+	byName := make(map[string]model.Detection)
+	for _, d := range detections {
+		if _, dup := byName[d.Technology]; dup {
+			t.Errorf("duplicate detection for %s", d.Technology)
+		}
+		byName[d.Technology] = d
+	}
+	if _, ok := byName["nginx"]; ok {
+		t.Error("nginx should be merged into Nginx")
+	}
+	if d := byName["Nginx"]; d.Version != "1.25.3" || d.Source != model.SourceHeadersOnly {
+		t.Errorf("Nginx = %+v, want the Wappalyzer detection", d)
+	}
+	if d := byName["Acme"]; d.Source != "test-static" {
+		t.Errorf("Acme source = %q, want test-static", d.Source)
+	}
+
+	if _, err := NewEngine([]string{"nope"}, EngineOptions{}); err == nil {
+		t.Error("NewEngine() with an unknown engine should fail")
+	}
+	failing, _ := NewEngine([]string{"test-failing"}, EngineOptions{})
+	if _, err := failing.Detect(headers, nil, ""); err == nil {
+		t.Error("Detect() should fail when every engine fails")
+	}
+}
+
+func TestMergeDetections(t *testing.T) {
+	merged := MergeDetections(
+		[]model.Detection{{Technology: "jQuery", Source: "wappalyzer", ConfidenceScore: 50, Categories: []string{"JavaScript libraries"}}},
+		[]model.Detection{{Technology: "jquery", Source: "jslib", Version: "3.5.1", ConfidenceScore: 100}},
+	)
+	if len(merged) != 1 {
+		t.Fatalf("MergeDetections() = %v, want one detection", merged)
+	}
+	d := merged[0]
+	if d.Source != "jslib" || d.Version != "3.5.1" || len(d.Categories) != 1 {
+		t.Errorf("merged = %+v, want the higher-confidence detection with categories filled in", d)
+	}
+}
+
+func TestFollowUpCache(t *testing.T) {
+	cache := NewFollowUpCache(2)
+	var fetches atomic.Int32
+	fetch := func() []model.Detection {
+		fetches.Add(1)
+		return []model.Detection{{Technology: "Spring Boot"}}
+	}
+
+	// Targets of one host share its /favicon.ico
+	icon := FollowUp{URL: "https://example.com/favicon.ico", Name: "favicon"}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if got := cache.Detect(icon, fetch); len(got) != 1 || got[0].Technology != "Spring Boot" {
+				t.Errorf("Detect() = %v, want Spring Boot", got)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := fetches.Load(); n != 1 {
+		t.Errorf("shared follow-up fetched %d times, want 1", n)
+	}
+
+	// Other hosts and evicted entries are fetched again
+	cache.Detect(FollowUp{URL: "https://example.org/favicon.ico", Name: "favicon"}, fetch)
+	cache.Detect(FollowUp{URL: "https://example.net/favicon.ico", Name: "favicon"}, fetch)
+	cache.Detect(icon, fetch)
+	if n := fetches.Load(); n != 4 {
+		t.Errorf("fetched %d times, want 4", n)
+	}
+}
@@ -0,0 +1,137 @@
+package detect
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Abhaythakor/hyperwapp/model"
+)
+
+// EngineTLS is the registry name of the TLS certificate engine.
+const EngineTLS = "tls"
+
+// tlsConfidence is lower than header fingerprints: a certificate names who issued
+// or terminates TLS, which is not always what serves the content.
+const tlsConfidence = 80
+
+// Certificate fields a TLS rule can match.
+const (
+	tlsFieldIssuer  = "issuer"  // Issuer CN and O
+	tlsFieldSubject = "subject" // Subject CN, O and OU
+	tlsFieldSAN     = "san"     // DNS names and IP addresses
+)
+
+// tlsRule maps a certificate pattern to a technology.
+type tlsRule struct {
+	field      string
+	pattern    *regexp.Regexp
+	technology string
+	categories []string
+}
+
+// tlsRules covers certificate authorities, CDNs, load balancers and hosting
+// providers. Names match the Wappalyzer technologies where one exists.
+var tlsRules = []tlsRule{
+	{tlsFieldIssuer, regexp.MustCompile(`(?i)^Let's Encrypt$`), "Let's Encrypt", []string{"SSL/TLS certificate authorities"}},
+	{tlsFieldIssuer, regexp.MustCompile(`(?i)^DigiCert`), "DigiCert", []string{"SSL/TLS certificate authorities"}},
+	{tlsFieldIssuer, regexp.MustCompile(`(?i)^(Sectigo|COMODO)`), "Sectigo", []string{"SSL/TLS certificate authorities"}},
+	{tlsFieldIssuer, regexp.MustCompile(`(?i)^GlobalSign`), "GlobalSign", []string{"SSL/TLS certificate authorities"}},
+	{tlsFieldIssuer, regexp.MustCompile(`(?i)^ZeroSSL`), "ZeroSSL", []string{"SSL/TLS certificate authorities"}},
+	{tlsFieldIssuer, regexp.MustCompile(`(?i)^Entrust`), "Entrust", []string{"SSL/TLS certificate authorities"}},
+	{tlsFieldIssuer, regexp.MustCompile(`(?i)^GoDaddy`), "GoDaddy", []string{"Hosting"}},
+	{tlsFieldIssuer, regexp.MustCompile(`(?i)^Amazon$`), "Amazon Web Services", []string{"PaaS"}},
+	{tlsFieldIssuer, regexp.MustCompile(`(?i)^Cloudflare`), "Cloudflare", []string{"CDN"}},
+	{tlsFieldIssuer, regexp.MustCompile(`(?i)^Microsoft Azure`), "Azure", []string{"PaaS"}},
+	{tlsFieldIssuer, regexp.MustCompile(`(?i)^Kubernetes Ingress Controller Fake Certificate$`), "Kubernetes", []string{"Containers"}},
+	{tlsFieldIssuer, regexp.MustCompile(`(?i)^TRAEFIK DEFAULT CERT$`), "Traefik", []string{"Reverse proxies"}},
+
+	{tlsFieldSubject, regexp.MustCompile(`(?i)^Cloudflare, Inc\.?$`), "Cloudflare", []string{"CDN"}},
+	{tlsFieldSubject, regexp.MustCompile(`(?i)^Akamai Technologies`), "Akamai", []string{"CDN"}},
+	{tlsFieldSubject, regexp.MustCompile(`(?i)^Fastly, Inc\.?$`), "Fastly", []string{"CDN"}},
+	{tlsFieldSubject, regexp.MustCompile(`(?i)^Incapsula`), "Imperva", []string{"Security", "CDN"}},
+	{tlsFieldSubject, regexp.MustCompile(`(?i)^Kubernetes Ingress Controller Fake Certificate$`), "Kubernetes", []string{"Containers"}},
+	{tlsFieldSubject, regexp.MustCompile(`(?i)^TRAEFIK DEFAULT CERT$`), "Traefik", []string{"Reverse proxies"}},
+
+	{tlsFieldSAN, regexp.MustCompile(`(?i)(^|\.)(sni\.)?cloudflaressl\.com$`), "Cloudflare", []string{"CDN"}},
+	{tlsFieldSAN, regexp.MustCompile(`(?i)\.cloudfront\.net$`), "Amazon CloudFront", []string{"CDN"}},
+	{tlsFieldSAN, regexp.MustCompile(`(?i)\.elb\.amazonaws\.com$`), "Amazon ELB", []string{"Load balancers"}},
+	{tlsFieldSAN, regexp.MustCompile(`(?i)\.s3[.-]([a-z0-9-]+\.)?amazonaws\.com$`), "Amazon S3", []string{"CDN"}},
+	{tlsFieldSAN, regexp.MustCompile(`(?i)\.(akamaized|akamaihd|edgekey|edgesuite)\.net$`), "Akamai", []string{"CDN"}},
+	{tlsFieldSAN, regexp.MustCompile(`(?i)\.(azureedge|azurefd)\.net$`), "Azure CDN", []string{"CDN"}},
+	{tlsFieldSAN, regexp.MustCompile(`(?i)\.azurewebsites\.net$`), "Azure", []string{"PaaS"}},
+	{tlsFieldSAN, regexp.MustCompile(`(?i)\.fastly\.net$|(^|\.)fastly\.com$`), "Fastly", []string{"CDN"}},
+	{tlsFieldSAN, regexp.MustCompile(`(?i)\.incapsula\.com$`), "Imperva", []string{"Security", "CDN"}},
+	{tlsFieldSAN, regexp.MustCompile(`(?i)\.herokuapp\.com$`), "Heroku", []string{"PaaS"}},
+	{tlsFieldSAN, regexp.MustCompile(`(?i)\.github\.io$`), "GitHub Pages", []string{"PaaS"}},
+	{tlsFieldSAN, regexp.MustCompile(`(?i)\.netlify\.(app|com)$`), "Netlify", []string{"PaaS", "CDN"}},
+	{tlsFieldSAN, regexp.MustCompile(`(?i)\.vercel\.app$`), "Vercel", []string{"PaaS"}},
+	{tlsFieldSAN, regexp.MustCompile(`(?i)\.myshopify\.com$`), "Shopify", []string{"Ecommerce"}},
+	{tlsFieldSAN, regexp.MustCompile(`(?i)\.wpengine\.com$`), "WP Engine", []string{"PaaS", "Hosting"}},
+	{tlsFieldSAN, regexp.MustCompile(`(?i)\.pantheonsite\.io$`), "Pantheon", []string{"PaaS"}},
+	{tlsFieldSAN, regexp.MustCompile(`(?i)\.squarespace\.com$`), "Squarespace", []string{"CMS"}},
+}
+
+// TLSEngine detects technologies from the certificate of an online target.
+type TLSEngine struct {
+	evidence bool
+}
+
+func init() {
+	RegisterEngine(EngineTLS, func(opts EngineOptions) (Engine, error) {
+		return &TLSEngine{evidence: opts.Evidence}, nil
+	})
+}
+
+// Detect does nothing: the TLS engine only looks at target metadata.
+func (e *TLSEngine) Detect(headers map[string][]string, body []byte, sourceHint string) ([]model.Detection, error) {
+	return nil, nil
+}
+
+// DetectTarget matches the TLS rules against the certificate of a target.
+func (e *TLSEngine) DetectTarget(target model.Target) ([]model.Detection, error) {
+	info := target.TLS
+	if info == nil {
+		return nil, nil
+	}
+
+	fields := map[string][]string{
+		tlsFieldIssuer:  append([]string{info.IssuerCN}, info.IssuerOrg...),
+		tlsFieldSubject: append(append([]string{info.SubjectCN}, info.SubjectOrg...), info.SubjectOU...),
+		tlsFieldSAN:     info.SANs,
+	}
+
+	now := time.Now().UTC()
+	seen := make(map[string]struct{})
+	var detections []model.Detection
+	for _, rule := range tlsRules {
+		if _, ok := seen[rule.technology]; ok {
+			continue
+		}
+		for _, value := range fields[rule.field] {
+			if value == "" || !rule.pattern.MatchString(value) {
+				continue
+			}
+			seen[rule.technology] = struct{}{}
+
+			d := model.Detection{
+				Technology:      rule.technology,
+				Categories:      rule.categories,
+				Source:          model.SourceTLS,
+				Stage:           EngineTLS,
+				Path:            "fingerprint",
+				Evidence:        EngineTLS,
+				ConfidenceScore: tlsConfidence,
+				Confidence:      model.ConfidenceBucket(tlsConfidence),
+				Timestamp:       now,
+			}
+			if e.evidence {
+				d.Path = "tls:" + rule.field
+				d.Evidence = truncateEvidence(strings.TrimSpace(value))
+			}
+			detections = append(detections, d)
+			break
+		}
+	}
+	return detections, nil
+}
@@ -0,0 +1,71 @@
+package detect
+
+import (
+	"testing"
+
+	"github.com/Abhaythakor/hyperwapp/model"
+)
+
+func TestTLSEngine(t *testing.T) {
+	tests := []struct {
+		name string
+		info *model.TLSInfo
+		want []string
+	}{
+		{
+			name: "Let's Encrypt on Netlify",
+			info: &model.TLSInfo{IssuerCN: "R3", IssuerOrg: []string{"Let's Encrypt"}, SANs: []string{"*.netlify.app"}},
+			want: []string{"Let's Encrypt", "Netlify"},
+		},
+		{
+			name: "AWS ACM behind CloudFront",
+			info: &model.TLSInfo{IssuerCN: "Amazon RSA 2048 M02", IssuerOrg: []string{"Amazon"}, SANs: []string{"example.com", "d111111abcdef8.cloudfront.net"}},
+			want: []string{"Amazon Web Services", "Amazon CloudFront"},
+		},
+		{
+			name: "Akamai edge certificate",
+			info: &model.TLSInfo{IssuerOrg: []string{"DigiCert Inc"}, SubjectOrg: []string{"Akamai Technologies, Inc."}, SANs: []string{"a248.e.akamai.net", "*.akamaized.net"}},
+			want: []string{"DigiCert", "Akamai"},
+		},
+		{
+			name: "Default ingress certificate",
+			info: &model.TLSInfo{IssuerCN: "Kubernetes Ingress Controller Fake Certificate", SubjectCN: "Kubernetes Ingress Controller Fake Certificate", SelfSigned: true},
+			want: []string{"Kubernetes"},
+		},
+		{
+			name: "Plain HTTP",
+			info: nil,
+			want: nil,
+		},
+	}
+
+	engine := &TLSEngine{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detections, err := engine.DetectTarget(model.Target{URL: "https://example.com", TLS: tt.info})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, d := range detections {
+				got = append(got, d.Technology)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("DetectTarget() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("DetectTarget() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestTLSEngineEvidence(t *testing.T) {
+	engine := &TLSEngine{evidence: true}
+	detections, _ := engine.DetectTarget(model.Target{TLS: &model.TLSInfo{SANs: []string{"shop.example.com", "acme.myshopify.com"}}})
+	if len(detections) != 1 || detections[0].Path != "tls:san" || detections[0].Evidence != "acme.myshopify.com" {
+		t.Errorf("DetectTarget() = %+v, want Shopify matched on its SAN", detections)
+	}
+}
@@ -14,7 +14,7 @@ const (
 	fingerprintsURL = "https://raw.githubusercontent.com/projectdiscovery/wappalyzergo/master/fingerprints_data.json"
 )
 
-// GetFingerprintsPath returns the local path where wappalyzergo looks for fingerprints.
+// GetFingerprintsPath returns the local path where --update stores the downloaded fingerprints.
 func GetFingerprintsPath() (string, error) {
 	home, err := os.UserHomeDir()
 	if err != nil {
@@ -33,49 +33,69 @@ func UpdateFingerprints() error {
 		return fmt.Errorf("could not determine fingerprints path: %w", err)
 	}
 
+	count, err := downloadValidated(fingerprintsURL, path, ValidateFingerprints)
+	if err != nil {
+		return err
+	}
+
+	util.Info("Downloaded %d technologies", count)
+	util.Info("Fingerprints updated successfully to %s", path)
+	return nil
+}
+
+// downloadValidated downloads url to path. The data is written to a temporary
+// file first and validated, so a bad download never replaces a working file.
+// validate returns the number of entries in the data.
+func downloadValidated(url, path string, validate func(data []byte) (int, error)) (int, error) {
 	// Create directory if it doesn't exist
 	dir := filepath.Dir(path)
 	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("could not create directory %s: %w", dir, err)
+		return 0, fmt.Errorf("could not create directory %s: %w", dir, err)
 	}
 
-	// Download file
-	resp, err := http.Get(fingerprintsURL)
+	resp, err := http.Get(url)
 	if err != nil {
-		return fmt.Errorf("failed to download fingerprints: %w", err)
+		return 0, fmt.Errorf("failed to download %s: %w", url, err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("bad status code: %d", resp.StatusCode)
+		return 0, fmt.Errorf("bad status code: %d", resp.StatusCode)
 	}
 
-	out, err := os.Create(path)
+	tmp, err := os.CreateTemp(dir, "download-*.json")
 	if err != nil {
-		return fmt.Errorf("could not create file %s: %w", path, err)
+		return 0, fmt.Errorf("could not create temporary file in %s: %w", dir, err)
 	}
-	defer out.Close()
+	defer os.Remove(tmp.Name())
 
-	_, err = io.Copy(out, resp.Body)
+	_, err = io.Copy(tmp, resp.Body)
+	tmp.Close()
 	if err != nil {
-		return fmt.Errorf("failed to save fingerprints: %w", err)
+		return 0, fmt.Errorf("failed to save %s: %w", url, err)
 	}
 
-	util.Info("Fingerprints updated successfully to %s", path)
-	return nil
-}
-
-// GetFingerprintsInfo returns information about the local fingerprints file.
-func GetFingerprintsInfo() string {
-	path, err := GetFingerprintsPath()
+	data, err := os.ReadFile(tmp.Name())
 	if err != nil {
-		return "Unknown"
+		return 0, fmt.Errorf("failed to read downloaded file: %w", err)
 	}
-
-	info, err := os.Stat(path)
+	count, err := validate(data)
 	if err != nil {
-		return "Not found (using embedded defaults)"
+		return 0, fmt.Errorf("downloaded file is invalid: %w", err)
 	}
 
-	return fmt.Sprintf("Last updated: %s", info.ModTime().Format("2006-01-02 15:04:05"))
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return 0, fmt.Errorf("could not replace %s: %w", path, err)
+	}
+	return count, nil
+}
+
+// GetFingerprintsInfo describes the fingerprints that will be used for detection,
+// given the --fingerprints path (empty for the default resolution).
+func GetFingerprintsInfo(path string) string {
+	source, err := ResolveFingerprints(path)
+	if err != nil {
+		return fmt.Sprintf("Unavailable (%v)", err)
+	}
+	return source.String()
 }
@@ -0,0 +1,305 @@
+package diff
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Abhaythakor/hyperwapp/model"
+)
+
+// Comparison keys.
+const (
+	ByURL    = "url"
+	ByDomain = "domain"
+)
+
+// Change types.
+const (
+	ChangeHostAdded      = "host_added"
+	ChangeHostRemoved    = "host_removed"
+	ChangeTechAdded      = "tech_added"
+	ChangeTechRemoved    = "tech_removed"
+	ChangeVersionChanged = "version_changed"
+)
+
+// Options controls how two result files are compared.
+type Options struct {
+	By        string // url | domain
+	ChunkSize int    // Records sorted in memory per chunk (0 = DefaultChunkSize)
+	TempDir   string // Directory for spilled chunks (empty = os.TempDir)
+}
+
+// Change is a single difference between the old and the new scan.
+type Change struct {
+	Type         string   `json:"type"`   // host_added | host_removed | tech_added | tech_removed | version_changed
+	Target       string   `json:"target"` // URL, or domain with --by domain
+	Domain       string   `json:"domain,omitempty"`
+	Technology   string   `json:"technology,omitempty"`
+	OldVersion   string   `json:"old_version,omitempty"`
+	NewVersion   string   `json:"new_version,omitempty"`
+	Technologies []string `json:"technologies,omitempty"` // Technologies of an added or removed host
+}
+
+// Summary counts the targets and changes seen during a comparison.
+type Summary struct {
+	OldTargets     int `json:"old_targets"`
+	NewTargets     int `json:"new_targets"`
+	HostsAdded     int `json:"hosts_added"`
+	HostsRemoved   int `json:"hosts_removed"`
+	TechAdded      int `json:"tech_added"`
+	TechRemoved    int `json:"tech_removed"`
+	VersionChanged int `json:"version_changed"`
+	Unchanged      int `json:"unchanged_targets"`
+}
+
+// Changes returns the total number of changes.
+func (s Summary) Changes() int {
+	return s.HostsAdded + s.HostsRemoved + s.TechAdded + s.TechRemoved + s.VersionChanged
+}
+
+// Writer receives changes as they are found.
+type Writer interface {
+	Write(c Change) error
+	Close(s Summary) error
+}
+
+// Compare streams both result files, sorts them on disk when they exceed the
+// chunk size and writes every change to w. Memory stays bounded by the chunk
+// size and the technologies of a single target.
+func Compare(oldPath, newPath string, opts Options, w Writer) (Summary, error) {
+	var summary Summary
+	switch opts.By {
+	case "":
+		opts.By = ByURL
+	case ByURL, ByDomain:
+	default:
+		return summary, fmt.Errorf("invalid comparison key %q (expected url or domain)", opts.By)
+	}
+
+	oldSorter, err := load(oldPath, opts)
+	if oldSorter != nil {
+		defer oldSorter.Cleanup()
+	}
+	if err != nil {
+		return summary, err
+	}
+	newSorter, err := load(newPath, opts)
+	if newSorter != nil {
+		defer newSorter.Cleanup()
+	}
+	if err != nil {
+		return summary, err
+	}
+
+	oldIt, err := oldSorter.Iterator()
+	if err != nil {
+		return summary, err
+	}
+	defer oldIt.Close()
+	newIt, err := newSorter.Iterator()
+	if err != nil {
+		return summary, err
+	}
+	defer newIt.Close()
+
+	oldGroups := &groupReader{it: oldIt}
+	newGroups := &groupReader{it: newIt}
+	oldGroup, err := oldGroups.Next()
+	if err != nil {
+		return summary, err
+	}
+	newGroup, err := newGroups.Next()
+	if err != nil {
+		return summary, err
+	}
+
+	for oldGroup != nil || newGroup != nil {
+		var changes []Change
+		switch {
+		case newGroup == nil || (oldGroup != nil && oldGroup.key < newGroup.key):
+			summary.OldTargets++
+			summary.HostsRemoved++
+			changes = append(changes, oldGroup.hostChange(ChangeHostRemoved))
+			if oldGroup, err = oldGroups.Next(); err != nil {
+				return summary, err
+			}
+		case oldGroup == nil || newGroup.key < oldGroup.key:
+			summary.NewTargets++
+			summary.HostsAdded++
+			changes = append(changes, newGroup.hostChange(ChangeHostAdded))
+			if newGroup, err = newGroups.Next(); err != nil {
+				return summary, err
+			}
+		default:
+			summary.OldTargets++
+			summary.NewTargets++
+			changes = compareGroups(oldGroup, newGroup, &summary)
+			if len(changes) == 0 {
+				summary.Unchanged++
+			}
+			if oldGroup, err = oldGroups.Next(); err != nil {
+				return summary, err
+			}
+			if newGroup, err = newGroups.Next(); err != nil {
+				return summary, err
+			}
+		}
+
+		for _, c := range changes {
+			if err := w.Write(c); err != nil {
+				return summary, err
+			}
+		}
+	}
+
+	return summary, w.Close(summary)
+}
+
+// load reads a result file into a sorter keyed by URL or domain.
+func load(path string, opts Options) (*sorter, error) {
+	s := newSorter(opts.ChunkSize, opts.TempDir)
+	err := readDetections(path, func(d model.Detection) error {
+		key := d.URL
+		if opts.By == ByDomain || key == "" {
+			key = d.Domain
+		}
+		if key == "" || d.Technology == "" {
+			return nil
+		}
+		return s.Add(record{Key: key, Domain: d.Domain, Tech: d.Technology, Version: d.Version})
+	})
+	return s, err
+}
+
+// techEntry holds the versions of one technology on one target.
+type techEntry struct {
+	name     string
+	versions []string
+}
+
+func (e *techEntry) addVersion(v string) {
+	if v == "" {
+		return
+	}
+	for _, existing := range e.versions {
+		if existing == v {
+			return
+		}
+	}
+	e.versions = append(e.versions, v)
+}
+
+func (e *techEntry) version() string {
+	sort.Strings(e.versions)
+	return strings.Join(e.versions, ", ")
+}
+
+// group is every technology seen on one target.
+type group struct {
+	key    string
+	domain string
+	techs  []*techEntry // Sorted case-insensitively, as produced by the sorter
+}
+
+func (g *group) hostChange(changeType string) Change {
+	c := Change{Type: changeType, Target: g.key, Domain: g.domain}
+	for _, t := range g.techs {
+		c.Technologies = append(c.Technologies, techLabel(t.name, t.version()))
+	}
+	return c
+}
+
+// groupReader collects consecutive records with the same key.
+type groupReader struct {
+	it      iterator
+	pending *record
+}
+
+func (gr *groupReader) Next() (*group, error) {
+	var first record
+	if gr.pending != nil {
+		first = *gr.pending
+		gr.pending = nil
+	} else {
+		r, ok, err := gr.it.Next()
+		if err != nil || !ok {
+			return nil, err
+		}
+		first = r
+	}
+
+	g := &group{key: first.Key, domain: first.Domain}
+	g.add(first)
+	for {
+		r, ok, err := gr.it.Next()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return g, nil
+		}
+		if r.Key != g.key {
+			gr.pending = &r
+			return g, nil
+		}
+		g.add(r)
+	}
+}
+
+func (g *group) add(r record) {
+	if n := len(g.techs); n > 0 && strings.EqualFold(g.techs[n-1].name, r.Tech) {
+		g.techs[n-1].addVersion(r.Version)
+		return
+	}
+	entry := &techEntry{name: r.Tech}
+	entry.addVersion(r.Version)
+	g.techs = append(g.techs, entry)
+}
+
+// compareGroups walks the sorted technologies of both sides of a target.
+func compareGroups(oldGroup, newGroup *group, summary *Summary) []Change {
+	var changes []Change
+	domain := newGroup.domain
+	i, j := 0, 0
+	for i < len(oldGroup.techs) || j < len(newGroup.techs) {
+		var cmp int
+		switch {
+		case i >= len(oldGroup.techs):
+			cmp = 1
+		case j >= len(newGroup.techs):
+			cmp = -1
+		default:
+			cmp = strings.Compare(strings.ToLower(oldGroup.techs[i].name), strings.ToLower(newGroup.techs[j].name))
+		}
+
+		switch {
+		case cmp < 0:
+			t := oldGroup.techs[i]
+			summary.TechRemoved++
+			changes = append(changes, Change{Type: ChangeTechRemoved, Target: oldGroup.key, Domain: domain, Technology: t.name, OldVersion: t.version()})
+			i++
+		case cmp > 0:
+			t := newGroup.techs[j]
+			summary.TechAdded++
+			changes = append(changes, Change{Type: ChangeTechAdded, Target: newGroup.key, Domain: domain, Technology: t.name, NewVersion: t.version()})
+			j++
+		default:
+			oldVersion, newVersion := oldGroup.techs[i].version(), newGroup.techs[j].version()
+			if oldVersion != newVersion {
+				summary.VersionChanged++
+				changes = append(changes, Change{Type: ChangeVersionChanged, Target: newGroup.key, Domain: domain, Technology: newGroup.techs[j].name, OldVersion: oldVersion, NewVersion: newVersion})
+			}
+			i++
+			j++
+		}
+	}
+	return changes
+}
+
+func techLabel(name, version string) string {
+	if version == "" {
+		return name
+	}
+	return name + ":" + version
+}
@@ -0,0 +1,146 @@
+package diff_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Abhaythakor/hyperwapp/diff"
+)
+
+type recorder struct {
+	changes []diff.Change
+	summary diff.Summary
+	closed  bool
+}
+
+func (r *recorder) Write(c diff.Change) error {
+	r.changes = append(r.changes, c)
+	return nil
+}
+
+func (r *recorder) Close(s diff.Summary) error {
+	r.summary = s
+	r.closed = true
+	return nil
+}
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+const oldJSONL = `{"domain":"a.com","url":"https://a.com","technology":"WordPress","version":"6.3"}
+{"domain":"a.com","url":"https://a.com","technology":"PHP"}
+{"domain":"a.com","url":"https://a.com","technology":"jQuery","version":"3.6.0"}
+{"domain":"b.com","url":"https://b.com","technology":"Nginx"}
+{"domain":"gone.com","url":"https://gone.com","technology":"IIS","version":"8.5"}
+not json
+`
+
+const newCSV = `domain,url,technology,version,categories,source,pack,stage,path,evidence,confidence,confidence_score,timestamp
+a.com,https://a.com,WordPress,6.4,CMS,wappalyzer,builtin,body,,,high,100,2026-01-01T00:00:00Z
+a.com,https://a.com,php,,,wappalyzer,builtin,header,,,high,100,2026-01-01T00:00:00Z
+a.com,https://a.com,Cloudflare,,,wappalyzer,builtin,header,,,high,100,2026-01-01T00:00:00Z
+b.com,https://b.com,Nginx,,,wappalyzer,builtin,header,,,high,100,2026-01-01T00:00:00Z
+new.com,https://new.com,Caddy,,,wappalyzer,builtin,header,,,high,100,2026-01-01T00:00:00Z
+`
+
+var wantChanges = []diff.Change{
+	{Type: diff.ChangeTechAdded, Target: "https://a.com", Domain: "a.com", Technology: "Cloudflare"},
+	{Type: diff.ChangeTechRemoved, Target: "https://a.com", Domain: "a.com", Technology: "jQuery", OldVersion: "3.6.0"},
+	{Type: diff.ChangeVersionChanged, Target: "https://a.com", Domain: "a.com", Technology: "WordPress", OldVersion: "6.3", NewVersion: "6.4"},
+	{Type: diff.ChangeHostRemoved, Target: "https://gone.com", Domain: "gone.com", Technologies: []string{"IIS:8.5"}},
+	{Type: diff.ChangeHostAdded, Target: "https://new.com", Domain: "new.com", Technologies: []string{"Caddy"}},
+}
+
+func TestCompare(t *testing.T) {
+	dir := t.TempDir()
+	oldPath := writeFile(t, dir, "old.jsonl", oldJSONL)
+	newPath := writeFile(t, dir, "new.csv", newCSV)
+
+	// A chunk size of 2 forces both inputs through the on-disk merge.
+	for _, chunkSize := range []int{0, 2} {
+		rec := &recorder{}
+		summary, err := diff.Compare(oldPath, newPath, diff.Options{ChunkSize: chunkSize, TempDir: dir}, rec)
+		if err != nil {
+			t.Fatalf("Compare() error = %v", err)
+		}
+		if !reflect.DeepEqual(rec.changes, wantChanges) {
+			t.Errorf("chunk size %d: changes = %+v; want %+v", chunkSize, rec.changes, wantChanges)
+		}
+		want := diff.Summary{OldTargets: 3, NewTargets: 3, HostsAdded: 1, HostsRemoved: 1, TechAdded: 1, TechRemoved: 1, VersionChanged: 1, Unchanged: 1}
+		if summary != want || rec.summary != want || !rec.closed {
+			t.Errorf("chunk size %d: summary = %+v; want %+v", chunkSize, summary, want)
+		}
+	}
+
+	leftovers, _ := filepath.Glob(filepath.Join(dir, "HyperWapp-diff-*"))
+	if len(leftovers) != 0 {
+		t.Errorf("chunk files were not cleaned up: %v", leftovers)
+	}
+}
+
+func TestCompareJSONByDomain(t *testing.T) {
+	dir := t.TempDir()
+	oldPath := writeFile(t, dir, "old.json", `{
+  "meta": {"tool": "HyperWapp", "mode": "all"},
+  "results": [
+    {"domain": "a.com", "url": "https://a.com/", "detections": [{"technology": "Nginx", "version": "1.24"}]},
+    {"domain": "a.com", "url": "https://a.com/blog", "detections": [{"technology": "WordPress"}]}
+  ]
+}`)
+	// Aggregated --domain output uses the Go field names.
+	newPath := writeFile(t, dir, "new.jsonl", `{"Domain":"a.com","URLs":["https://a.com/"],"Detections":[{"domain":"a.com","url":"https://a.com/","technology":"Nginx","version":"1.25"},{"domain":"a.com","url":"https://a.com/blog","technology":"WordPress"}]}
+`)
+
+	rec := &recorder{}
+	if _, err := diff.Compare(oldPath, newPath, diff.Options{By: diff.ByDomain}, rec); err != nil {
+		t.Fatalf("Compare() error = %v", err)
+	}
+	want := []diff.Change{
+		{Type: diff.ChangeVersionChanged, Target: "a.com", Domain: "a.com", Technology: "Nginx", OldVersion: "1.24", NewVersion: "1.25"},
+	}
+	if !reflect.DeepEqual(rec.changes, want) {
+		t.Errorf("changes = %+v; want %+v", rec.changes, want)
+	}
+}
+
+func TestCompareInvalidKey(t *testing.T) {
+	if _, err := diff.Compare("a", "b", diff.Options{By: "ip"}, &recorder{}); err == nil {
+		t.Error("Compare() should reject an unknown comparison key")
+	}
+}
+
+func TestWriters(t *testing.T) {
+	summary := diff.Summary{OldTargets: 3, NewTargets: 3, HostsAdded: 1}
+	for format, want := range map[string]string{
+		"cli":   "[+ host] https://new.com [Caddy]",
+		"jsonl": `{"type":"summary","old_targets":3`,
+		"md":    "| host_added | https://new.com | Caddy |  |  |",
+	} {
+		var buf bytes.Buffer
+		w, err := diff.NewWriter(format, &buf, false)
+		if err != nil {
+			t.Fatalf("NewWriter(%s) error = %v", format, err)
+		}
+		w.Write(wantChanges[4])
+		if err := w.Close(summary); err != nil {
+			t.Fatalf("%s Close() error = %v", format, err)
+		}
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("%s output missing %q:\n%s", format, want, buf.String())
+		}
+	}
+
+	if _, err := diff.NewWriter("xml", &bytes.Buffer{}, false); err == nil {
+		t.Error("NewWriter() should reject unsupported formats")
+	}
+}
@@ -0,0 +1,240 @@
+package diff
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Abhaythakor/hyperwapp/model"
+)
+
+// Result file formats understood by the reader.
+const (
+	FormatJSONL = "jsonl"
+	FormatJSON  = "json"
+	FormatCSV   = "csv"
+)
+
+// resultLine covers every record shape HyperWapp writes: a single detection
+// (JSONL), a per-URL result (JSON) and an aggregated domain (--domain).
+type resultLine struct {
+	model.Detection
+	Detections []model.Detection `json:"detections"`
+}
+
+// DetectFormat picks the format of a result file from its extension, falling
+// back to sniffing the first line.
+func DetectFormat(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jsonl", ".ndjson":
+		return FormatJSONL, nil
+	case ".json":
+		return FormatJSON, nil
+	case ".csv":
+		return FormatCSV, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	line, err := bufio.NewReader(file).ReadBytes('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	line = bytes.TrimSpace(line)
+	switch {
+	case len(line) == 0:
+		return FormatJSONL, nil // Empty file, any reader will do
+	case line[0] == '{' && json.Valid(line):
+		return FormatJSONL, nil
+	case line[0] == '{' || line[0] == '[':
+		return FormatJSON, nil
+	case bytes.HasPrefix(line, []byte("domain,")):
+		return FormatCSV, nil
+	}
+	return "", fmt.Errorf("cannot detect the format of %s (expected jsonl, json or csv)", path)
+}
+
+// readDetections streams every detection of a result file to fn.
+func readDetections(path string, fn func(model.Detection) error) error {
+	format, err := DetectFormat(path)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReaderSize(file, 1024*1024)
+	switch format {
+	case FormatJSON:
+		err = readJSON(reader, fn)
+	case FormatCSV:
+		err = readCSV(reader, fn)
+	default:
+		err = readJSONL(reader, fn)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return nil
+}
+
+func readJSONL(r io.Reader, fn func(model.Detection) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var res resultLine
+		if err := json.Unmarshal(line, &res); err != nil {
+			continue // Skip malformed lines
+		}
+		if err := emit(res, fn); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// readJSON walks the {"meta": ..., "results": [...]} document one result at a
+// time so large reports never have to fit in memory.
+func readJSON(r io.Reader, fn func(model.Detection) error) error {
+	dec := json.NewDecoder(r)
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok == json.Delim('[') {
+		return readJSONArray(dec, fn)
+	}
+	if tok != json.Delim('{') {
+		return fmt.Errorf("unexpected JSON token %v", tok)
+	}
+
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if key != "results" {
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return err
+			}
+			continue
+		}
+		if tok, err := dec.Token(); err != nil {
+			return err
+		} else if tok == nil {
+			continue // "results": null
+		} else if tok != json.Delim('[') {
+			return fmt.Errorf("results must be an array")
+		}
+		if err := readJSONArray(dec, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readJSONArray(dec *json.Decoder, fn func(model.Detection) error) error {
+	for dec.More() {
+		var res resultLine
+		if err := dec.Decode(&res); err != nil {
+			return err
+		}
+		if err := emit(res, fn); err != nil {
+			return err
+		}
+	}
+	_, err := dec.Token() // Closing ]
+	return err
+}
+
+func readCSV(r io.Reader, fn func(model.Detection) error) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	techCol, ok := columns["technology"]
+	if !ok {
+		return fmt.Errorf("CSV header has no technology column")
+	}
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return record[i]
+		}
+		return ""
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if techCol >= len(record) {
+			continue
+		}
+		d := model.Detection{
+			Domain:     field(record, "domain"),
+			URL:        field(record, "url"),
+			Technology: record[techCol],
+			Version:    field(record, "version"),
+		}
+		if err := fn(d); err != nil {
+			return err
+		}
+	}
+}
+
+// emit flattens a result record into its detections, filling the domain and
+// URL from the enclosing result when a detection omits them.
+func emit(res resultLine, fn func(model.Detection) error) error {
+	if len(res.Detections) == 0 {
+		if res.Technology == "" {
+			return nil
+		}
+		return fn(res.Detection)
+	}
+	for _, d := range res.Detections {
+		if d.Domain == "" {
+			d.Domain = res.Domain
+		}
+		if d.URL == "" {
+			d.URL = res.URL
+		}
+		if err := fn(d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
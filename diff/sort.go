@@ -0,0 +1,229 @@
+package diff
+
+import (
+	"bufio"
+	"container/heap"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// DefaultChunkSize is the number of records sorted in memory before they are
+// spilled to a temporary file.
+const DefaultChunkSize = 500000
+
+// record is the compact form of a detection used while diffing.
+type record struct {
+	Key     string // URL, or domain with --by domain
+	Domain  string
+	Tech    string
+	Version string
+}
+
+func (r record) less(o record) bool {
+	if r.Key != o.Key {
+		return r.Key < o.Key
+	}
+	return strings.ToLower(r.Tech) < strings.ToLower(o.Tech)
+}
+
+// sanitize keeps the tab separated chunk encoding unambiguous.
+var sanitize = strings.NewReplacer("\t", " ", "\n", " ", "\r", " ")
+
+func (r record) encode(w *bufio.Writer) error {
+	_, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+		sanitize.Replace(r.Key), sanitize.Replace(r.Domain), sanitize.Replace(r.Tech), sanitize.Replace(r.Version))
+	return err
+}
+
+func decodeRecord(line string) (record, bool) {
+	parts := strings.SplitN(line, "\t", 4)
+	if len(parts) != 4 {
+		return record{}, false
+	}
+	return record{Key: parts[0], Domain: parts[1], Tech: parts[2], Version: parts[3]}, true
+}
+
+// iterator yields records in sorted order.
+type iterator interface {
+	Next() (record, bool, error)
+	Close()
+}
+
+// sorter is an external merge sort: records are sorted in chunks of at most
+// chunkSize, spilled to temporary files and merged back when iterated.
+type sorter struct {
+	chunkSize int
+	tempDir   string
+	buf       []record
+	chunks    []string
+}
+
+func newSorter(chunkSize int, tempDir string) *sorter {
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+	return &sorter{chunkSize: chunkSize, tempDir: tempDir}
+}
+
+func (s *sorter) Add(r record) error {
+	s.buf = append(s.buf, r)
+	if len(s.buf) >= s.chunkSize {
+		return s.spill()
+	}
+	return nil
+}
+
+func (s *sorter) sortBuf() {
+	sort.SliceStable(s.buf, func(i, j int) bool { return s.buf[i].less(s.buf[j]) })
+}
+
+func (s *sorter) spill() error {
+	s.sortBuf()
+	file, err := os.CreateTemp(s.tempDir, "HyperWapp-diff-*.tsv")
+	if err != nil {
+		return fmt.Errorf("failed to create diff chunk: %w", err)
+	}
+	s.chunks = append(s.chunks, file.Name())
+
+	w := bufio.NewWriterSize(file, 1024*1024)
+	for _, r := range s.buf {
+		if err := r.encode(w); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	s.buf = s.buf[:0]
+	return file.Close()
+}
+
+// Iterator returns the sorted records. Inputs that fit in a single chunk are
+// never written to disk.
+func (s *sorter) Iterator() (iterator, error) {
+	if len(s.chunks) == 0 {
+		s.sortBuf()
+		return &sliceIterator{records: s.buf}, nil
+	}
+	if len(s.buf) > 0 {
+		if err := s.spill(); err != nil {
+			return nil, err
+		}
+	}
+
+	m := &mergeIterator{}
+	for _, path := range s.chunks {
+		file, err := os.Open(path)
+		if err != nil {
+			m.Close()
+			return nil, err
+		}
+		c := &chunkReader{file: file, reader: bufio.NewReaderSize(file, 256*1024)}
+		m.readers = append(m.readers, c)
+		if err := m.push(c); err != nil {
+			m.Close()
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// Cleanup removes the spilled chunk files.
+func (s *sorter) Cleanup() {
+	for _, path := range s.chunks {
+		os.Remove(path)
+	}
+	s.chunks = nil
+	s.buf = nil
+}
+
+type sliceIterator struct {
+	records []record
+	pos     int
+}
+
+func (it *sliceIterator) Next() (record, bool, error) {
+	if it.pos >= len(it.records) {
+		return record{}, false, nil
+	}
+	it.pos++
+	return it.records[it.pos-1], true, nil
+}
+
+func (it *sliceIterator) Close() {}
+
+type chunkReader struct {
+	file   *os.File
+	reader *bufio.Reader
+	head   record
+}
+
+func (c *chunkReader) advance() (bool, error) {
+	for {
+		line, err := c.reader.ReadString('\n')
+		if err == io.EOF && line == "" {
+			return false, nil
+		}
+		if err != nil && err != io.EOF {
+			return false, err
+		}
+		if r, ok := decodeRecord(strings.TrimSuffix(line, "\n")); ok {
+			c.head = r
+			return true, nil
+		}
+	}
+}
+
+// mergeIterator k-way merges the chunk files using a min-heap of their heads.
+type mergeIterator struct {
+	readers []*chunkReader
+	heap    chunkHeap
+}
+
+func (m *mergeIterator) push(c *chunkReader) error {
+	ok, err := c.advance()
+	if err != nil {
+		return err
+	}
+	if ok {
+		heap.Push(&m.heap, c)
+	}
+	return nil
+}
+
+func (m *mergeIterator) Next() (record, bool, error) {
+	if m.heap.Len() == 0 {
+		return record{}, false, nil
+	}
+	c := heap.Pop(&m.heap).(*chunkReader)
+	r := c.head
+	if err := m.push(c); err != nil {
+		return record{}, false, err
+	}
+	return r, true, nil
+}
+
+func (m *mergeIterator) Close() {
+	for _, c := range m.readers {
+		c.file.Close()
+	}
+}
+
+type chunkHeap []*chunkReader
+
+func (h chunkHeap) Len() int           { return len(h) }
+func (h chunkHeap) Less(i, j int) bool { return h[i].head.less(h[j].head) }
+func (h chunkHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *chunkHeap) Push(x any)        { *h = append(*h, x.(*chunkReader)) }
+func (h *chunkHeap) Pop() any {
+	old := *h
+	n := len(old)
+	c := old[n-1]
+	*h = old[:n-1]
+	return c
+}
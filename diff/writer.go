@@ -0,0 +1,143 @@
+package diff
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Abhaythakor/hyperwapp/util"
+)
+
+// NewWriter returns the writer for an output format: cli, jsonl or md.
+func NewWriter(format string, out io.Writer, colorize bool) (Writer, error) {
+	switch strings.ToLower(format) {
+	case "cli", "":
+		return NewCLIWriter(out, colorize), nil
+	case "jsonl":
+		return NewJSONLWriter(out), nil
+	case "md", "markdown":
+		return NewMDWriter(out), nil
+	}
+	return nil, fmt.Errorf("unsupported diff format %q (expected cli, jsonl or md)", format)
+}
+
+// CLIWriter prints one colored line per change.
+type CLIWriter struct {
+	out   *bufio.Writer
+	color *util.Colorizer
+}
+
+// NewCLIWriter creates a new CLIWriter.
+func NewCLIWriter(out io.Writer, colorize bool) *CLIWriter {
+	return &CLIWriter{out: bufio.NewWriter(out), color: util.NewColorizer(colorize)}
+}
+
+func (w *CLIWriter) Write(c Change) error {
+	var err error
+	switch c.Type {
+	case ChangeHostAdded:
+		_, err = fmt.Fprintf(w.out, "%s %s [%s]\n", w.color.Green("[+ host]"), w.color.Cyan(c.Target), strings.Join(c.Technologies, ", "))
+	case ChangeHostRemoved:
+		_, err = fmt.Fprintf(w.out, "%s %s [%s]\n", w.color.Red("[- host]"), w.color.Cyan(c.Target), strings.Join(c.Technologies, ", "))
+	case ChangeTechAdded:
+		_, err = fmt.Fprintf(w.out, "%s %s %s\n", w.color.Green("[+]"), w.color.Cyan(c.Target), techLabel(c.Technology, c.NewVersion))
+	case ChangeTechRemoved:
+		_, err = fmt.Fprintf(w.out, "%s %s %s\n", w.color.Red("[-]"), w.color.Cyan(c.Target), techLabel(c.Technology, c.OldVersion))
+	case ChangeVersionChanged:
+		_, err = fmt.Fprintf(w.out, "%s %s %s %s -> %s\n", w.color.Yellow("[~]"), w.color.Cyan(c.Target), c.Technology, versionOrUnknown(c.OldVersion), versionOrUnknown(c.NewVersion))
+	}
+	return err
+}
+
+func (w *CLIWriter) Close(s Summary) error {
+	fmt.Fprintf(w.out, "\n[+] %s: %d -> %d\n", w.color.Cyan("Targets"), s.OldTargets, s.NewTargets)
+	fmt.Fprintf(w.out, "[+] %s: %d added, %d removed\n", w.color.Cyan("Hosts"), s.HostsAdded, s.HostsRemoved)
+	fmt.Fprintf(w.out, "[+] %s: %d added, %d removed, %d version changes\n", w.color.Cyan("Technologies"), s.TechAdded, s.TechRemoved, s.VersionChanged)
+	fmt.Fprintf(w.out, "[+] %s: %d\n", w.color.Cyan("Unchanged Targets"), s.Unchanged)
+	return w.out.Flush()
+}
+
+// JSONLWriter writes one JSON object per change followed by a summary record.
+type JSONLWriter struct {
+	out     *bufio.Writer
+	encoder *json.Encoder
+}
+
+// NewJSONLWriter creates a new JSONLWriter.
+func NewJSONLWriter(out io.Writer) *JSONLWriter {
+	buf := bufio.NewWriterSize(out, 1024*1024)
+	return &JSONLWriter{out: buf, encoder: json.NewEncoder(buf)}
+}
+
+func (w *JSONLWriter) Write(c Change) error {
+	return w.encoder.Encode(c)
+}
+
+func (w *JSONLWriter) Close(s Summary) error {
+	record := struct {
+		Type string `json:"type"`
+		Summary
+	}{Type: "summary", Summary: s}
+	if err := w.encoder.Encode(record); err != nil {
+		return err
+	}
+	return w.out.Flush()
+}
+
+// MDWriter streams the changes as a Markdown table followed by a summary.
+type MDWriter struct {
+	out    *bufio.Writer
+	header bool
+}
+
+// NewMDWriter creates a new MDWriter.
+func NewMDWriter(out io.Writer) *MDWriter {
+	return &MDWriter{out: bufio.NewWriterSize(out, 1024*1024)}
+}
+
+var mdEscaper = strings.NewReplacer("|", "\\|", "\n", " ")
+
+func (w *MDWriter) writeHeader() {
+	if w.header {
+		return
+	}
+	w.header = true
+	fmt.Fprintf(w.out, "# HyperWapp Scan Diff\n\n")
+	fmt.Fprintf(w.out, "| Change | Target | Technology | Old Version | New Version |\n")
+	fmt.Fprintf(w.out, "|---|---|---|---|---|\n")
+}
+
+func (w *MDWriter) Write(c Change) error {
+	w.writeHeader()
+	tech := c.Technology
+	if len(c.Technologies) > 0 {
+		tech = strings.Join(c.Technologies, ", ")
+	}
+	_, err := fmt.Fprintf(w.out, "| %s | %s | %s | %s | %s |\n",
+		c.Type, mdEscaper.Replace(c.Target), mdEscaper.Replace(tech), mdEscaper.Replace(c.OldVersion), mdEscaper.Replace(c.NewVersion))
+	return err
+}
+
+func (w *MDWriter) Close(s Summary) error {
+	if !w.header {
+		fmt.Fprintf(w.out, "# HyperWapp Scan Diff\n\nNo changes.\n")
+	}
+	fmt.Fprintf(w.out, "\n## Summary\n\n")
+	fmt.Fprintf(w.out, "- **Targets:** %d -> %d\n", s.OldTargets, s.NewTargets)
+	fmt.Fprintf(w.out, "- **Hosts added:** %d\n", s.HostsAdded)
+	fmt.Fprintf(w.out, "- **Hosts removed:** %d\n", s.HostsRemoved)
+	fmt.Fprintf(w.out, "- **Technologies added:** %d\n", s.TechAdded)
+	fmt.Fprintf(w.out, "- **Technologies removed:** %d\n", s.TechRemoved)
+	fmt.Fprintf(w.out, "- **Version changes:** %d\n", s.VersionChanged)
+	fmt.Fprintf(w.out, "- **Unchanged targets:** %d\n", s.Unchanged)
+	return w.out.Flush()
+}
+
+func versionOrUnknown(v string) string {
+	if v == "" {
+		return "unknown"
+	}
+	return v
+}
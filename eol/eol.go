@@ -0,0 +1,208 @@
+package eol
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Abhaythakor/hyperwapp/model"
+	"github.com/Abhaythakor/hyperwapp/util"
+)
+
+// Bundled snapshot of endoflife.date release cycles, refreshed by --update.
+//
+//go:embed data/eol.json
+var embeddedData []byte
+
+// SourceEmbedded names the dataset compiled into the binary.
+const SourceEmbedded = "embedded"
+
+// Cycle is a release cycle in the endoflife.date API format.
+type Cycle struct {
+	Cycle  string     `json:"cycle"`            // 7.4
+	EOL    dateOrBool `json:"eol"`              // "2022-11-28", true or false
+	Latest string     `json:"latest,omitempty"` // 7.4.33
+}
+
+// dateOrBool holds endoflife.date's "eol" field, which is either a date or a
+// boolean when the date is unknown (true) or not planned yet (false).
+type dateOrBool struct {
+	Ended bool
+	Date  string
+}
+
+func (v *dateOrBool) UnmarshalJSON(data []byte) error {
+	var b bool
+	if err := json.Unmarshal(data, &b); err == nil {
+		*v = dateOrBool{Ended: b}
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("eol must be a date or a boolean: %s", data)
+	}
+	if _, err := time.Parse(time.DateOnly, s); err != nil {
+		return fmt.Errorf("invalid eol date %q", s)
+	}
+	*v = dateOrBool{Date: s}
+	return nil
+}
+
+func (v dateOrBool) MarshalJSON() ([]byte, error) {
+	if v.Date != "" {
+		return json.Marshal(v.Date)
+	}
+	return json.Marshal(v.Ended)
+}
+
+// Dataset maps technologies to endoflife.date products and their cycles.
+type Dataset struct {
+	Technologies map[string]string  `json:"technologies"` // Wappalyzer name -> endoflife.date product
+	Products     map[string][]Cycle `json:"products"`
+}
+
+// Parse decodes and validates a dataset.
+func Parse(data []byte) (*Dataset, error) {
+	var ds Dataset
+	if err := json.Unmarshal(data, &ds); err != nil {
+		return nil, fmt.Errorf("invalid EOL dataset: %w", err)
+	}
+	if len(ds.Technologies) == 0 || len(ds.Products) == 0 {
+		return nil, fmt.Errorf("EOL dataset has no technologies or products")
+	}
+	return &ds, nil
+}
+
+// DB flags detections as supported, EOL or unknown.
+type DB struct {
+	techs  map[string]string // lowercased technology -> product
+	cycles map[string][]Cycle
+	now    func() time.Time
+}
+
+// New indexes a dataset.
+func New(ds *Dataset) *DB {
+	db := &DB{
+		techs:  make(map[string]string, len(ds.Technologies)),
+		cycles: ds.Products,
+		now:    time.Now,
+	}
+	for tech, product := range ds.Technologies {
+		db.techs[strings.ToLower(tech)] = product
+	}
+	return db
+}
+
+// Load reads the dataset from path, the file downloaded by --update, or the
+// embedded copy, in that order. It also returns where the data came from.
+func Load(path string) (*DB, string, error) {
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read EOL dataset: %w", err)
+		}
+		ds, err := Parse(data)
+		if err != nil {
+			return nil, "", err
+		}
+		return New(ds), path, nil
+	}
+
+	if downloaded, err := GetDatasetPath(); err == nil {
+		if data, err := os.ReadFile(downloaded); err == nil {
+			ds, err := Parse(data)
+			if err == nil {
+				return New(ds), downloaded, nil
+			}
+			util.Warn("Ignoring EOL dataset %s: %v", downloaded, err)
+		}
+	}
+
+	ds, err := Parse(embeddedData)
+	if err != nil {
+		return nil, "", err
+	}
+	return New(ds), SourceEmbedded, nil
+}
+
+// Status is the support status of one technology version.
+type Status struct {
+	Status string // model.EOLSupported | model.EOLEnded | model.EOLUnknown
+	Date   string // EOL date, if known
+	Latest string // Latest release of the matching cycle, if known
+}
+
+// Lookup returns the support status of a technology version. The cycle is
+// the one sharing the most leading version segments ("7.4" for 7.4.3, "1.1.1"
+// for 1.1.1k).
+func (db *DB) Lookup(tech, version string) Status {
+	unknown := Status{Status: model.EOLUnknown}
+	if version == "" {
+		return unknown
+	}
+	product, ok := db.techs[strings.ToLower(tech)]
+	if !ok {
+		return unknown
+	}
+
+	segments := strings.Split(version, ".")
+	var best *Cycle
+	bestLen := 0
+	for i := range db.cycles[product] {
+		c := &db.cycles[product][i]
+		cycleSegments := strings.Split(c.Cycle, ".")
+		if len(cycleSegments) <= bestLen || len(cycleSegments) > len(segments) {
+			continue
+		}
+		match := true
+		for j, s := range cycleSegments {
+			if !segmentMatches(segments[j], s) {
+				match = false
+				break
+			}
+		}
+		if match {
+			best, bestLen = c, len(cycleSegments)
+		}
+	}
+	if best == nil {
+		return unknown
+	}
+
+	status := Status{Status: model.EOLSupported, Date: best.EOL.Date, Latest: best.Latest}
+	switch {
+	case best.EOL.Date != "":
+		if best.EOL.Date <= db.now().UTC().Format(time.DateOnly) {
+			status.Status = model.EOLEnded
+		}
+	case best.EOL.Ended:
+		status.Status = model.EOLEnded
+	}
+	return status
+}
+
+// segmentMatches compares a version segment to a cycle segment, ignoring a
+// letter suffix on the version ("1k" matches "1", "10" does not).
+func segmentMatches(segment, cycle string) bool {
+	if !strings.HasPrefix(segment, cycle) {
+		return false
+	}
+	rest := segment[len(cycle):]
+	return rest == "" || rest[0] < '0' || rest[0] > '9'
+}
+
+// Enrich sets the EOL fields of each detection in place.
+func (db *DB) Enrich(detections []model.Detection) {
+	if db == nil {
+		return
+	}
+	for i := range detections {
+		status := db.Lookup(detections[i].Technology, detections[i].Version)
+		detections[i].EOLStatus = status.Status
+		detections[i].EOLDate = status.Date
+		detections[i].LatestVersion = status.Latest
+	}
+}
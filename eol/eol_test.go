@@ -0,0 +1,173 @@
+package eol
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Abhaythakor/hyperwapp/model"
+)
+
+const dataset = `{
+  "technologies": {"PHP": "php", "OpenSSL": "openssl", "jQuery": "jquery"},
+  "products": {
+    "php": [
+      {"cycle": "8.3", "eol": "2027-12-31"},
+      {"cycle": "7.4", "eol": "2022-11-28", "latest": "7.4.33"}
+    ],
+    "openssl": [
+      {"cycle": "1.1", "eol": "2019-09-11"},
+      {"cycle": "1.1.1", "eol": "2023-09-11", "latest": "1.1.1w"}
+    ],
+    "jquery": [
+      {"cycle": "3", "eol": false},
+      {"cycle": "1", "eol": true}
+    ]
+  }
+}`
+
+func newTestDB(t *testing.T) *DB {
+	t.Helper()
+	ds, err := Parse([]byte(dataset))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	db := New(ds)
+	db.now = func() time.Time { return time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC) }
+	return db
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := map[string]string{
+		"invalid JSON": `{"technologies":`,
+		"empty":        `{}`,
+		"bad date":     `{"technologies": {"PHP": "php"}, "products": {"php": [{"cycle": "7.4", "eol": "28/11/2022"}]}}`,
+		"bad eol":      `{"technologies": {"PHP": "php"}, "products": {"php": [{"cycle": "7.4", "eol": 1}]}}`,
+	}
+	for name, data := range tests {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("%s: Parse() should fail", name)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	db := newTestDB(t)
+	tests := []struct {
+		tech, version string
+		want          Status
+	}{
+		{"PHP", "7.4.3", Status{model.EOLEnded, "2022-11-28", "7.4.33"}},
+		{"php", "8.3.1", Status{model.EOLSupported, "2027-12-31", ""}},
+		{"PHP", "8.3", Status{model.EOLSupported, "2027-12-31", ""}},
+		{"PHP", "7", Status{Status: model.EOLUnknown}},
+		{"PHP", "5.6.40", Status{Status: model.EOLUnknown}},
+		{"PHP", "", Status{Status: model.EOLUnknown}},
+		{"OpenSSL", "1.1.1k", Status{model.EOLEnded, "2023-09-11", "1.1.1w"}},
+		{"PHP", "7.40", Status{Status: model.EOLUnknown}},
+		{"OpenSSL", "1.1.1", Status{model.EOLEnded, "2023-09-11", "1.1.1w"}},
+		{"OpenSSL", "1.1.0", Status{model.EOLEnded, "2019-09-11", ""}},
+		{"jQuery", "3.7.1", Status{Status: model.EOLSupported}},
+		{"jQuery", "1.12.4", Status{Status: model.EOLEnded}},
+		{"Nginx", "1.25.3", Status{Status: model.EOLUnknown}},
+	}
+	for _, tt := range tests {
+		if got := db.Lookup(tt.tech, tt.version); got != tt.want {
+			t.Errorf("Lookup(%q, %q) = %+v, want %+v", tt.tech, tt.version, got, tt.want)
+		}
+	}
+
+	db.now = func() time.Time { return time.Date(2028, 1, 1, 0, 0, 0, 0, time.UTC) }
+	if got := db.Lookup("PHP", "8.3.1"); got.Status != model.EOLEnded {
+		t.Errorf("Lookup() after the EOL date = %+v, want eol", got)
+	}
+}
+
+func TestEnrich(t *testing.T) {
+	detections := []model.Detection{
+		{Technology: "PHP", Version: "7.4.3"},
+		{Technology: "Nginx"},
+	}
+	newTestDB(t).Enrich(detections)
+	if d := detections[0]; d.EOLStatus != model.EOLEnded || d.EOLDate != "2022-11-28" || d.LatestVersion != "7.4.33" {
+		t.Errorf("Enrich() = %+v", d)
+	}
+	if d := detections[1]; d.EOLStatus != model.EOLUnknown || d.EOLDate != "" {
+		t.Errorf("Enrich() = %+v", d)
+	}
+
+	var db *DB
+	db.Enrich(detections) // A nil DB is a no-op.
+}
+
+func TestEmbeddedDataset(t *testing.T) {
+	ds, err := Parse(embeddedData)
+	if err != nil {
+		t.Fatalf("embedded dataset: %v", err)
+	}
+	for tech, product := range ds.Technologies {
+		if len(ds.Products[product]) == 0 {
+			t.Errorf("%s maps to %s, which has no cycles", tech, product)
+		}
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	path, err := GetDatasetPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.MkdirAll(filepath.Dir(path), 0755)
+	if err := os.WriteFile(path, []byte(dataset), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/php.json":
+			w.Write([]byte(`[{"cycle": "8.4", "eol": "2028-12-31", "latest": "8.4.1"}, {"cycle": "7.4", "eol": "2022-11-28"}]`))
+		case "/jquery.json":
+			w.Write([]byte(`[]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	updated, total, err := update(path, srv.URL+"/%s.json")
+	if err != nil {
+		t.Fatalf("update() error = %v", err)
+	}
+	if updated != 1 || total != 3 {
+		t.Errorf("update() refreshed %d/%d products, want 1/3", updated, total)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ds Dataset
+	if err := json.Unmarshal(data, &ds); err != nil {
+		t.Fatalf("updated dataset: %v", err)
+	}
+	if len(ds.Products["php"]) != 2 || ds.Products["php"][0].Cycle != "8.4" {
+		t.Errorf("php cycles = %+v", ds.Products["php"])
+	}
+	if len(ds.Products["openssl"]) != 2 || len(ds.Products["jquery"]) != 2 {
+		t.Errorf("products that failed to download should keep their cycles: %+v", ds.Products)
+	}
+
+	db, source, err := Load("")
+	if err != nil || source != path {
+		t.Fatalf("Load() = %s, %v; want the updated dataset", source, err)
+	}
+	if got := db.Lookup("PHP", "8.4.1"); got.Status != model.EOLSupported {
+		t.Errorf("Lookup() on the updated dataset = %+v", got)
+	}
+}
@@ -0,0 +1,121 @@
+package eol
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/Abhaythakor/hyperwapp/util"
+)
+
+// apiURL is the endoflife.date endpoint listing the cycles of a product.
+const apiURL = "https://endoflife.date/api/%s.json"
+
+// GetDatasetPath returns the local path where --update stores the EOL dataset.
+func GetDatasetPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "hyperwapp", "eol.json"), nil
+}
+
+// Update refreshes the cycles of every product in the current dataset from
+// endoflife.date. Products that fail to download keep their previous cycles.
+func Update() error {
+	util.Info("Updating end-of-life dataset...")
+
+	path, err := GetDatasetPath()
+	if err != nil {
+		return fmt.Errorf("could not determine EOL dataset path: %w", err)
+	}
+	updated, total, err := update(path, apiURL)
+	if err != nil {
+		return err
+	}
+
+	util.Info("EOL dataset updated to %s (%d/%d products refreshed)", path, updated, total)
+	return nil
+}
+
+// update downloads the products of the dataset currently in use and writes
+// the merged result to path.
+func update(path, urlFormat string) (int, int, error) {
+	ds, err := currentDataset()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	products := make([]string, 0, len(ds.Products))
+	for product := range ds.Products {
+		products = append(products, product)
+	}
+	sort.Strings(products)
+
+	updated := 0
+	for _, product := range products {
+		cycles, err := fetchCycles(fmt.Sprintf(urlFormat, product))
+		if err != nil {
+			util.Warn("Failed to update EOL data for %s: %v", product, err)
+			continue
+		}
+		ds.Products[product] = cycles
+		updated++
+	}
+	if updated == 0 {
+		return 0, len(products), fmt.Errorf("no EOL product could be downloaded")
+	}
+
+	data, err := json.MarshalIndent(ds, "", "  ")
+	if err != nil {
+		return 0, 0, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return 0, 0, fmt.Errorf("could not create directory %s: %w", filepath.Dir(path), err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return 0, 0, err
+	}
+	return updated, len(products), os.Rename(tmp, path)
+}
+
+// currentDataset returns the dataset Load("") would use.
+func currentDataset() (*Dataset, error) {
+	if downloaded, err := GetDatasetPath(); err == nil {
+		if data, err := os.ReadFile(downloaded); err == nil {
+			if ds, err := Parse(data); err == nil {
+				return ds, nil
+			}
+		}
+	}
+	return Parse(embeddedData)
+}
+
+func fetchCycles(url string) ([]Cycle, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("bad status code: %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var cycles []Cycle
+	if err := json.Unmarshal(data, &cycles); err != nil {
+		return nil, fmt.Errorf("invalid cycles: %w", err)
+	}
+	if len(cycles) == 0 {
+		return nil, fmt.Errorf("no cycles")
+	}
+	return cycles, nil
+}
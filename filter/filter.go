@@ -0,0 +1,146 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Abhaythakor/hyperwapp/model"
+)
+
+// Filter decides which detections are passed on to the CLI and file writers.
+type Filter struct {
+	OnlyVersioned     bool     // Keep only detections with a known version
+	Categories        []string // Keep only detections in one of these categories
+	ExcludeCategories []string // Drop detections in any of these categories
+	MinConfidence     int      // Drop detections scoring below this confidence (0-100)
+	MatchTech         []string // Keep only these technologies (exact, glob or regex)
+	ExcludeTech       []string // Drop these technologies (exact, glob or regex)
+	MatchMode         string   // tech (default) | target
+	OnlyEOL           bool     // Keep only detections whose version is end-of-life
+
+	matchTech   []techPattern
+	excludeTech []techPattern
+}
+
+// Match modes for MatchTech and ExcludeTech.
+const (
+	// MatchModeTech keeps the matching detections of every target.
+	MatchModeTech = "tech"
+	// MatchModeTarget keeps every detection of the targets with at least one
+	// matching technology and none excluded, and drops the other targets entirely.
+	MatchModeTarget = "target"
+)
+
+// Compile validates the technology patterns. It must be called before Keep or
+// Apply when MatchTech or ExcludeTech are set.
+func (f *Filter) Compile() error {
+	switch f.MatchMode {
+	case "":
+		f.MatchMode = MatchModeTech
+	case MatchModeTech, MatchModeTarget:
+	default:
+		return fmt.Errorf("invalid match mode %q (use %s or %s)", f.MatchMode, MatchModeTech, MatchModeTarget)
+	}
+
+	var err error
+	if f.matchTech, err = compileTechPatterns(f.MatchTech); err != nil {
+		return err
+	}
+	f.excludeTech, err = compileTechPatterns(f.ExcludeTech)
+	return err
+}
+
+// Enabled reports whether the filter would drop anything.
+func (f *Filter) Enabled() bool {
+	return f != nil && (f.OnlyVersioned || len(f.Categories) > 0 || len(f.ExcludeCategories) > 0 || f.MinConfidence > 0 ||
+		len(f.matchTech) > 0 || len(f.excludeTech) > 0 || f.OnlyEOL)
+}
+
+// Keep reports whether a single detection passes the filter.
+func (f *Filter) Keep(d model.Detection) bool {
+	if f.OnlyVersioned && d.Version == "" {
+		return false
+	}
+	if f.MinConfidence > 0 && d.ConfidenceScore < f.MinConfidence {
+		return false
+	}
+	if f.OnlyEOL && d.EOLStatus != model.EOLEnded {
+		return false
+	}
+	if len(f.Categories) > 0 && !hasCategory(d.Categories, f.Categories) {
+		return false
+	}
+	if len(f.ExcludeCategories) > 0 && hasCategory(d.Categories, f.ExcludeCategories) {
+		return false
+	}
+	if f.MatchMode != MatchModeTarget {
+		if len(f.matchTech) > 0 && !matchAny(f.matchTech, d.Technology) {
+			return false
+		}
+		if matchAny(f.excludeTech, d.Technology) {
+			return false
+		}
+	}
+	return true
+}
+
+// Apply filters a batch of detections in place and returns the kept ones.
+func (f *Filter) Apply(detections []model.Detection) []model.Detection {
+	if !f.Enabled() {
+		return detections
+	}
+
+	kept := detections[:0]
+	for _, d := range detections {
+		if f.Keep(d) {
+			kept = append(kept, d)
+		}
+	}
+	if f.MatchMode == MatchModeTarget && (len(f.matchTech) > 0 || len(f.excludeTech) > 0) {
+		kept = f.applyTargets(kept)
+	}
+	return kept
+}
+
+// applyTargets implements MatchModeTarget: a batch may hold several targets, so
+// the technology patterns are evaluated per URL.
+func (f *Filter) applyTargets(detections []model.Detection) []model.Detection {
+	matched := make(map[string]bool)
+	excluded := make(map[string]bool)
+	for _, d := range detections {
+		key := targetKey(d)
+		if matchAny(f.excludeTech, d.Technology) {
+			excluded[key] = true
+		}
+		if len(f.matchTech) == 0 || matchAny(f.matchTech, d.Technology) {
+			matched[key] = true
+		}
+	}
+
+	kept := detections[:0]
+	for _, d := range detections {
+		if key := targetKey(d); matched[key] && !excluded[key] {
+			kept = append(kept, d)
+		}
+	}
+	return kept
+}
+
+func targetKey(d model.Detection) string {
+	if d.URL != "" {
+		return d.URL
+	}
+	return d.Domain
+}
+
+// hasCategory reports whether any of the categories is in the wanted list (case-insensitive).
+func hasCategory(categories, wanted []string) bool {
+	for _, c := range categories {
+		for _, w := range wanted {
+			if strings.EqualFold(c, w) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,161 @@
+package filter_test
+
+import (
+	"testing"
+
+	"github.com/Abhaythakor/hyperwapp/filter"
+	"github.com/Abhaythakor/hyperwapp/model"
+)
+
+func TestFilterApply(t *testing.T) {
+	detections := func() []model.Detection {
+		return []model.Detection{
+			{Technology: "WordPress", Version: "6.4.2", Categories: []string{"Blogs", "CMS"}, ConfidenceScore: 100, EOLStatus: model.EOLEnded},
+			{Technology: "Cloudflare", Categories: []string{"CDN"}, ConfidenceScore: 50, EOLStatus: model.EOLUnknown},
+			{Technology: "Google Analytics", Version: "GA4", Categories: []string{"Analytics"}, ConfidenceScore: 75, EOLStatus: model.EOLSupported},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter *filter.Filter
+		want   []string
+	}{
+		{
+			name:   "Disabled",
+			filter: &filter.Filter{},
+			want:   []string{"WordPress", "Cloudflare", "Google Analytics"},
+		},
+		{
+			name:   "Only versioned",
+			filter: &filter.Filter{OnlyVersioned: true},
+			want:   []string{"WordPress", "Google Analytics"},
+		},
+		{
+			name:   "Category",
+			filter: &filter.Filter{Categories: []string{"cms", "cdn"}},
+			want:   []string{"WordPress", "Cloudflare"},
+		},
+		{
+			name:   "Exclude category",
+			filter: &filter.Filter{ExcludeCategories: []string{"Analytics"}},
+			want:   []string{"WordPress", "Cloudflare"},
+		},
+		{
+			name:   "Min confidence",
+			filter: &filter.Filter{MinConfidence: 75},
+			want:   []string{"WordPress", "Google Analytics"},
+		},
+		{
+			name:   "Combined",
+			filter: &filter.Filter{OnlyVersioned: true, ExcludeCategories: []string{"Analytics"}},
+			want:   []string{"WordPress"},
+		},
+		{
+			name:   "Only EOL",
+			filter: &filter.Filter{OnlyEOL: true},
+			want:   []string{"WordPress"},
+		},
+		{
+			name:   "Match exact",
+			filter: &filter.Filter{MatchTech: []string{"wordpress"}},
+			want:   []string{"WordPress"},
+		},
+		{
+			name:   "Match glob",
+			filter: &filter.Filter{MatchTech: []string{"Google *", "cloud*"}},
+			want:   []string{"Cloudflare", "Google Analytics"},
+		},
+		{
+			name:   "Match regex",
+			filter: &filter.Filter{MatchTech: []string{"/^(word|cloud)/"}},
+			want:   []string{"WordPress", "Cloudflare"},
+		},
+		{
+			name:   "Exclude tech",
+			filter: &filter.Filter{ExcludeTech: []string{"re:analytics$"}},
+			want:   []string{"WordPress", "Cloudflare"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.filter.Compile(); err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+			got := tt.filter.Apply(detections())
+			if len(got) != len(tt.want) {
+				t.Fatalf("Apply() kept %d detections; want %d (%v)", len(got), len(tt.want), got)
+			}
+			for i, d := range got {
+				if d.Technology != tt.want[i] {
+					t.Errorf("Apply()[%d] = %s; want %s", i, d.Technology, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFilterTargetMode(t *testing.T) {
+	batch := func() []model.Detection {
+		return []model.Detection{
+			{URL: "https://ci.example.com", Technology: "Jenkins"},
+			{URL: "https://ci.example.com", Technology: "Java"},
+			{URL: "https://git.example.com", Technology: "GitLab"},
+			{URL: "https://git.example.com", Technology: "Cloudflare"},
+			{URL: "https://www.example.com", Technology: "WordPress"},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter *filter.Filter
+		want   []string
+	}{
+		{
+			name:   "Tech mode keeps only matches",
+			filter: &filter.Filter{MatchTech: []string{"Jenkins", "GitLab"}},
+			want:   []string{"Jenkins", "GitLab"},
+		},
+		{
+			name:   "Target mode keeps whole matching targets",
+			filter: &filter.Filter{MatchTech: []string{"Jenkins", "GitLab"}, MatchMode: filter.MatchModeTarget},
+			want:   []string{"Jenkins", "Java", "GitLab", "Cloudflare"},
+		},
+		{
+			name:   "Target mode exclusion drops the target",
+			filter: &filter.Filter{MatchTech: []string{"Jenkins", "GitLab"}, ExcludeTech: []string{"Cloudflare"}, MatchMode: filter.MatchModeTarget},
+			want:   []string{"Jenkins", "Java"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.filter.Compile(); err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+			got := tt.filter.Apply(batch())
+			if len(got) != len(tt.want) {
+				t.Fatalf("Apply() kept %v; want %v", got, tt.want)
+			}
+			for i, d := range got {
+				if d.Technology != tt.want[i] {
+					t.Errorf("Apply()[%d] = %s; want %s", i, d.Technology, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFilterCompileErrors(t *testing.T) {
+	for _, f := range []*filter.Filter{
+		{MatchTech: []string{"/[unclosed/"}},
+		{ExcludeTech: []string{"[a-"}},
+		{MatchTech: []string{" "}},
+		{MatchMode: "domain"},
+	} {
+		if err := f.Compile(); err == nil {
+			t.Errorf("Compile(%+v) should fail", f)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package filter
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// techPattern matches technology names. A pattern is a regular expression when
+// written as /expr/ or re:expr, a glob when it contains *, ? or [, and an exact
+// name otherwise. All forms are case-insensitive.
+type techPattern struct {
+	exact string
+	glob  string
+	regex *regexp.Regexp
+}
+
+func compileTechPattern(pattern string) (techPattern, error) {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" {
+		return techPattern{}, fmt.Errorf("empty technology pattern")
+	}
+
+	expr, isRegex := strings.CutPrefix(pattern, "re:")
+	if !isRegex && len(pattern) > 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		expr, isRegex = pattern[1:len(pattern)-1], true
+	}
+	if isRegex {
+		re, err := regexp.Compile("(?i)" + expr)
+		if err != nil {
+			return techPattern{}, fmt.Errorf("invalid technology regex %q: %w", pattern, err)
+		}
+		return techPattern{regex: re}, nil
+	}
+
+	if strings.ContainsAny(pattern, "*?[") {
+		glob := strings.ToLower(pattern)
+		if _, err := path.Match(glob, ""); err != nil {
+			return techPattern{}, fmt.Errorf("invalid technology glob %q: %w", pattern, err)
+		}
+		return techPattern{glob: glob}, nil
+	}
+	return techPattern{exact: pattern}, nil
+}
+
+func (p techPattern) match(tech string) bool {
+	switch {
+	case p.regex != nil:
+		return p.regex.MatchString(tech)
+	case p.glob != "":
+		ok, _ := path.Match(p.glob, strings.ToLower(tech))
+		return ok
+	default:
+		return strings.EqualFold(p.exact, tech)
+	}
+}
+
+func compileTechPatterns(patterns []string) ([]techPattern, error) {
+	compiled := make([]techPattern, 0, len(patterns))
+	for _, p := range patterns {
+		c, err := compileTechPattern(p)
+		if err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, c)
+	}
+	return compiled, nil
+}
+
+func matchAny(patterns []techPattern, tech string) bool {
+	for _, p := range patterns {
+		if p.match(tech) {
+			return true
+		}
+	}
+	return false
+}
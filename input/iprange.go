@@ -0,0 +1,257 @@
+package input
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"net/netip"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MaxRangeSize is the largest number of addresses a single CIDR or dash range
+// may expand to (a /8), guarding against typos such as an IPv6 /64.
+const MaxRangeSize = 1 << 24
+
+// Range is an inclusive range of IP addresses.
+type Range struct {
+	First, Last netip.Addr
+}
+
+// isRange reports inputs written as a CIDR ("10.0.0.0/24") or dash range
+// ("10.0.0.1-10.0.0.50" or "10.0.0.1-50"). Single addresses are not ranges.
+func isRange(input string) bool {
+	if strings.Contains(input, "://") {
+		return false
+	}
+	if prefix, _, ok := strings.Cut(input, "/"); ok {
+		_, err := netip.ParseAddr(prefix)
+		return err == nil
+	}
+	first, _, ok := strings.Cut(input, "-")
+	if !ok {
+		return false
+	}
+	_, err := netip.ParseAddr(strings.TrimSpace(first))
+	return err == nil
+}
+
+// ParseRange parses a CIDR, a dash range or a single IP address.
+func ParseRange(input string) (Range, error) {
+	input = strings.TrimSpace(input)
+	if strings.Contains(input, "/") {
+		prefix, err := netip.ParsePrefix(input)
+		if err != nil {
+			return Range{}, fmt.Errorf("invalid CIDR %q", input)
+		}
+		prefix = prefix.Masked()
+		first := prefix.Addr().Unmap()
+		bits := prefix.Bits()
+		if prefix.Addr().Is4In6() {
+			bits -= 96
+		}
+		return checkRange(Range{First: first, Last: lastInPrefix(first, bits)}, input)
+	}
+
+	firstText, lastText, isDash := strings.Cut(input, "-")
+	first, err := netip.ParseAddr(strings.TrimSpace(firstText))
+	if err != nil {
+		return Range{}, fmt.Errorf("invalid IP address %q", firstText)
+	}
+	first = first.Unmap()
+	if !isDash {
+		return Range{First: first, Last: first}, nil
+	}
+
+	lastText = strings.TrimSpace(lastText)
+	last, err := netip.ParseAddr(lastText)
+	if err != nil {
+		// Short form: the last octet of an IPv4 address, "10.0.0.1-50"
+		octet, convErr := strconv.Atoi(lastText)
+		if convErr != nil || !first.Is4() || octet < 0 || octet > 255 {
+			return Range{}, fmt.Errorf("invalid range end %q", lastText)
+		}
+		b := first.As4()
+		b[3] = byte(octet)
+		last = netip.AddrFrom4(b)
+	}
+	last = last.Unmap()
+	if first.BitLen() != last.BitLen() || last.Less(first) {
+		return Range{}, fmt.Errorf("invalid range %q", input)
+	}
+	return checkRange(Range{First: first, Last: last}, input)
+}
+
+func checkRange(r Range, input string) (Range, error) {
+	if r.Size() > MaxRangeSize {
+		return Range{}, fmt.Errorf("range %q is larger than %d addresses", input, MaxRangeSize)
+	}
+	return r, nil
+}
+
+// lastInPrefix returns the last address of the prefix starting at first.
+func lastInPrefix(first netip.Addr, bits int) netip.Addr {
+	b := first.AsSlice()
+	for i := range b {
+		hostBits := min(max(8*(i+1)-bits, 0), 8)
+		b[i] |= byte(1<<hostBits - 1)
+	}
+	last, _ := netip.AddrFromSlice(b)
+	return last
+}
+
+// Size returns the number of addresses in the range, saturating at math.MaxUint64.
+func (r Range) Size() uint64 {
+	d := distance(r.First, r.Last)
+	if d == math.MaxUint64 {
+		return d
+	}
+	return d + 1
+}
+
+// Each calls fn for every address of the range in order, until fn returns false.
+func (r Range) Each(fn func(netip.Addr) bool) bool {
+	for addr := r.First; ; addr = addr.Next() {
+		if !fn(addr) {
+			return false
+		}
+		if addr == r.Last {
+			return true
+		}
+	}
+}
+
+// distance returns b - a for a <= b of the same family, saturating at math.MaxUint64.
+func distance(a, b netip.Addr) uint64 {
+	a16, b16 := a.As16(), b.As16()
+	var aHigh, aLow, bHigh, bLow uint64
+	for i := 0; i < 8; i++ {
+		aHigh, bHigh = aHigh<<8|uint64(a16[i]), bHigh<<8|uint64(b16[i])
+		aLow, bLow = aLow<<8|uint64(a16[i+8]), bLow<<8|uint64(b16[i+8])
+	}
+	switch {
+	case aHigh == bHigh:
+		return bLow - aLow
+	case bHigh == aHigh+1 && bLow < aLow:
+		return bLow - aLow // Wraps around to the right value
+	default:
+		return math.MaxUint64
+	}
+}
+
+// AddressSet is a set of IP addresses, such as the --exclude list. A nil
+// *AddressSet is empty.
+type AddressSet struct {
+	ranges []Range // Sorted and disjoint
+}
+
+// NewAddressSet parses IPs, CIDRs and dash ranges. Each spec may be a
+// comma-separated list or the path of a file with one entry per line.
+func NewAddressSet(specs []string) (*AddressSet, error) {
+	var ranges []Range
+	add := func(entry string) error {
+		entry = strings.TrimSpace(entry)
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			return nil
+		}
+		r, err := ParseRange(entry)
+		if err != nil {
+			return err
+		}
+		ranges = append(ranges, r)
+		return nil
+	}
+
+	for _, spec := range specs {
+		if info, err := os.Stat(spec); err == nil && !info.IsDir() {
+			file, err := os.Open(spec)
+			if err != nil {
+				return nil, err
+			}
+			scanner := bufio.NewScanner(file)
+			for scanner.Scan() {
+				if err := add(scanner.Text()); err != nil {
+					file.Close()
+					return nil, fmt.Errorf("%s: %w", spec, err)
+				}
+			}
+			file.Close()
+			if err := scanner.Err(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		for _, entry := range strings.Split(spec, ",") {
+			if err := add(entry); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(ranges) == 0 {
+		return nil, nil
+	}
+
+	// Merge overlapping and adjacent ranges so Overlap can sum intersections
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].First.Less(ranges[j].First) })
+	merged := ranges[:1]
+	for _, r := range ranges[1:] {
+		last := &merged[len(merged)-1]
+		if r.First.BitLen() == last.Last.BitLen() && (!last.Last.Less(r.First) || last.Last.Next() == r.First) {
+			if last.Last.Less(r.Last) {
+				last.Last = r.Last
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return &AddressSet{ranges: merged}, nil
+}
+
+// Contains reports whether addr is in the set.
+func (s *AddressSet) Contains(addr netip.Addr) bool {
+	if s == nil {
+		return false
+	}
+	addr = addr.Unmap()
+	i := sort.Search(len(s.ranges), func(i int) bool { return !s.ranges[i].Last.Less(addr) })
+	return i < len(s.ranges) && !addr.Less(s.ranges[i].First)
+}
+
+// ContainsURL reports whether the host of rawURL is an IP address in the set.
+// Hostnames are not resolved.
+func (s *AddressSet) ContainsURL(rawURL string) bool {
+	if s == nil {
+		return false
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	addr, err := netip.ParseAddr(u.Hostname())
+	return err == nil && s.Contains(addr)
+}
+
+// Overlap returns the number of addresses of r in the set.
+func (s *AddressSet) Overlap(r Range) uint64 {
+	if s == nil {
+		return 0
+	}
+	var n uint64
+	for _, x := range s.ranges {
+		if x.First.BitLen() != r.First.BitLen() || x.Last.Less(r.First) || r.Last.Less(x.First) {
+			continue
+		}
+		first, last := x.First, x.Last
+		if first.Less(r.First) {
+			first = r.First
+		}
+		if r.Last.Less(last) {
+			last = r.Last
+		}
+		n += Range{First: first, Last: last}.Size()
+	}
+	return n
+}
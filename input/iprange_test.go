@@ -0,0 +1,132 @@
+package input_test
+
+import (
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Abhaythakor/hyperwapp/input"
+	"github.com/Abhaythakor/hyperwapp/model"
+)
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		in          string
+		first, last string
+		size        uint64
+	}{
+		{"10.0.0.0/24", "10.0.0.0", "10.0.0.255", 256},
+		{"10.0.0.77/30", "10.0.0.76", "10.0.0.79", 4},
+		{"10.1.0.0/16", "10.1.0.0", "10.1.255.255", 65536},
+		{"10.0.0.1-10.0.1.10", "10.0.0.1", "10.0.1.10", 266},
+		{"10.0.0.1-50", "10.0.0.1", "10.0.0.50", 50},
+		{"192.168.1.7", "192.168.1.7", "192.168.1.7", 1},
+		{"2001:db8::/120", "2001:db8::", "2001:db8::ff", 256},
+		{"::ffff:10.0.0.0/120", "10.0.0.0", "10.0.0.255", 256},
+	}
+	for _, tt := range tests {
+		r, err := input.ParseRange(tt.in)
+		if err != nil {
+			t.Errorf("ParseRange(%q) error = %v", tt.in, err)
+			continue
+		}
+		if r.First.String() != tt.first || r.Last.String() != tt.last || r.Size() != tt.size {
+			t.Errorf("ParseRange(%q) = %s-%s (%d), want %s-%s (%d)", tt.in, r.First, r.Last, r.Size(), tt.first, tt.last, tt.size)
+		}
+	}
+
+	for _, in := range []string{"10.0.0.0/33", "10.0.0.9-5", "10.0.0.1-300", "10.0.0.1-::1", "2001:db8::/64", "0.0.0.0/0"} {
+		if _, err := input.ParseRange(in); err == nil {
+			t.Errorf("ParseRange(%q) should fail", in)
+		}
+	}
+}
+
+func TestAddressSet(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "exclude.txt")
+	os.WriteFile(file, []byte("# gateways\n10.0.0.1\n\n10.0.0.200-10.0.1.5\n"), 0644)
+	set, err := input.NewAddressSet([]string{"10.0.0.10/31,10.0.0.12", file})
+	if err != nil {
+		t.Fatalf("NewAddressSet() error = %v", err)
+	}
+	for addr, want := range map[string]bool{
+		"10.0.0.1": true, "10.0.0.2": false, "10.0.0.11": true, "10.0.0.12": true, "10.0.0.13": false,
+		"10.0.1.0": true, "10.0.1.6": false, "::ffff:10.0.0.1": true, "::1": false,
+	} {
+		if got := set.Contains(netip.MustParseAddr(addr)); got != want {
+			t.Errorf("Contains(%s) = %v, want %v", addr, got, want)
+		}
+	}
+	if !set.ContainsURL("https://10.0.0.1:8443/") || set.ContainsURL("https://example.com/") {
+		t.Error("ContainsURL() should match IP literal hosts only")
+	}
+
+	r, _ := input.ParseRange("10.0.0.0/24")
+	if got := set.Overlap(r); got != 1+3+56 {
+		t.Errorf("Overlap(10.0.0.0/24) = %d, want 60", got)
+	}
+
+	if _, err := input.NewAddressSet([]string{"10.0.0.0/24,example.com"}); err == nil {
+		t.Error("NewAddressSet() with a hostname should fail")
+	}
+	if set, err := input.NewAddressSet(nil); set != nil || err != nil || set.Contains(netip.MustParseAddr("10.0.0.1")) {
+		t.Errorf("NewAddressSet(nil) = %v, %v, want an empty set", set, err)
+	}
+}
+
+func TestSourceExpandsRanges(t *testing.T) {
+	list := filepath.Join(t.TempDir(), "targets.txt")
+	os.WriteFile(list, []byte("https://a.com\n10.0.0.0/30\n10.0.0.9:8080\nb.com\n"), 0644)
+	exclude, _ := input.NewAddressSet([]string{"10.0.0.2,10.0.0.9"})
+
+	source, err := input.ResolveSource(list, input.ProbeOptions{Ports: []int{80, 8443}}, exclude)
+	if err != nil {
+		t.Fatalf("ResolveSource() error = %v", err)
+	}
+	var urls []string
+	source.Each(func(target model.Target) bool {
+		urls = append(urls, target.URL)
+		if target.URL == "https://10.0.0.1:80" && (target.Probe != "10.0.0.0/30" || target.Fallback != "http://10.0.0.1") {
+			t.Errorf("range target = %+v", target)
+		}
+		return true
+	})
+	want := []string{
+		"https://a.com",
+		"https://10.0.0.0:80", "https://10.0.0.0:8443",
+		"https://10.0.0.1:80", "https://10.0.0.1:8443",
+		"https://10.0.0.3:80", "https://10.0.0.3:8443",
+		"https://b.com:80", "https://b.com:8443",
+	}
+	if len(urls) != len(want) || int(source.Count()) != len(want) {
+		t.Fatalf("Each() = %v (Count %d), want %v", urls, source.Count(), want)
+	}
+	for i := range want {
+		if urls[i] != want[i] {
+			t.Errorf("target %d = %s, want %s", i, urls[i], want[i])
+		}
+	}
+
+	stopped := 0
+	source.Each(func(model.Target) bool { stopped++; return stopped < 3 })
+	if stopped != 3 {
+		t.Errorf("Each() went on for %d targets after fn returned false", stopped)
+	}
+}
+
+func TestSourceCountsLargeRanges(t *testing.T) {
+	exclude, _ := input.NewAddressSet([]string{"10.1.0.0/24"})
+	source, err := input.ResolveSource("10.1.0.0/16", input.ProbeOptions{Policy: input.ProbeBoth}, exclude)
+	if err != nil {
+		t.Fatalf("ResolveSource() error = %v", err)
+	}
+	if got, want := source.Count(), uint32((65536-256)*2); got != want {
+		t.Errorf("Count() = %d, want %d", got, want)
+	}
+	var n uint32
+	source.Each(func(model.Target) bool { n++; return true })
+	if n != source.Count() {
+		t.Errorf("Each() yielded %d targets, Count() = %d", n, source.Count())
+	}
+}
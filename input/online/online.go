@@ -1,68 +1,325 @@
 package online
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"net/netip"
 	"sync"
+	"syscall"
 	"time"
 
+	"github.com/Abhaythakor/hyperwapp/detect"
 	"github.com/Abhaythakor/hyperwapp/model"
 	"github.com/Abhaythakor/hyperwapp/util"
 )
 
+// DefaultMaxBodySize is the default limit on bytes read from a response body.
+const DefaultMaxBodySize = 10 * 1024 * 1024
+
 var (
 	defaultClient *http.Client
 	once          sync.Once
+	oneShotClient *http.Client
+	oneShotOnce   sync.Once
 )
 
+// ErrExcluded is returned for connections to an excluded address.
+var ErrExcluded = errors.New("address is excluded")
+
+// excludeKey carries Options.Exclude in the request context down to the dialer.
+type excludeKey struct{}
+
+// checkExcluded is a net.Dialer ControlContext hook refusing the addresses
+// excluded by the fetch that dials; address is the resolved IP and port.
+func checkExcluded(ctx context.Context, network, address string, _ syscall.RawConn) error {
+	excluded, _ := ctx.Value(excludeKey{}).(func(netip.Addr) bool)
+	if excluded == nil {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && excluded(addr.Unmap()) {
+		return fmt.Errorf("%s: %w", host, ErrExcluded)
+	}
+	return nil
+}
+
+// Response is the result of fetching an online target.
+type Response struct {
+	URL        string // URL the response came from, after redirects
+	StatusCode int
+	Headers    map[string][]string
+	Body       []byte         // nil for headers-only fetches and skipped binary bodies
+	Truncated  bool           // Body was cut at Options.MaxBodySize
+	TLS        *model.TLSInfo // nil for plain HTTP; see TargetTLS for the target's own
+	Redirects  []*Response    // Redirect responses followed to reach this one, in order
+	Failure    *StatusError   // Retryable status still returned after all retries
+}
+
+// Options controls how a target is fetched.
+type Options struct {
+	HeadersOnly bool            // Send HEAD (falling back to an aborted ranged GET) and skip the body
+	MaxBodySize int64           // Stop reading the body after this many bytes (0 = unlimited)
+	SkipBinary  bool            // Do not download bodies with a binary Content-Type
+	Request     *Request        // Headers, cookies and User-Agent sent (nil = default User-Agent only)
+	Redirects   *RedirectPolicy // nil = up to DefaultMaxRedirects to any host
+	OneShot     bool            // Do not keep the connection alive, for hosts contacted once such as probes
+
+	// Exclude refuses connections to the addresses it returns true for,
+	// whichever URL, hostname or redirect leads there (nil = none). It is
+	// checked when a connection is dialed, so it should not change while
+	// the shared client keeps connections alive.
+	Exclude func(netip.Addr) bool
+
+	// Retries is the number of extra attempts after a retryable failure (see
+	// Retryable). When set, 429/502/503 responses are retried too; once
+	// retries run out the last such response is returned with its Failure
+	// set, so challenge and maintenance pages are still scanned.
+	Retries    int
+	RetryDelay time.Duration                               // Base backoff delay (0 = DefaultRetryDelay)
+	Wait       func(ctx context.Context, url string) error // Called before each retry, e.g. a rate limiter
+}
+
 // GetClient returns a shared HTTP client configured for high-concurrency scanning.
 func GetClient(timeout int) *http.Client {
 	once.Do(func() {
-		transport := &http.Transport{
-			Proxy: http.ProxyFromEnvironment,
-			MaxIdleConns:        500,
-			MaxIdleConnsPerHost: 100,
-			IdleConnTimeout:     90 * time.Second,
-			TLSHandshakeTimeout: 10 * time.Second,
-			DisableKeepAlives:   false,
-		}
-		defaultClient = &http.Client{
-			Transport: transport,
-			Timeout:   time.Duration(timeout) * time.Second,
-		}
+		defaultClient = newClient(timeout, false)
 	})
 	return defaultClient
 }
 
-// FetchOnline fetches the content of a URL and returns headers and body.
-func FetchOnline(ctx context.Context, target model.Target, timeout int) (map[string][]string, []byte, error) {
-	client := GetClient(timeout)
+// getOneShotClient returns a shared client that closes every connection after
+// its request. net/http keeps some bookkeeping per host:port it ever dialed
+// through a keep-alive transport, which adds up when probing every address of
+// a /16 that is contacted once.
+func getOneShotClient(timeout int) *http.Client {
+	oneShotOnce.Do(func() {
+		oneShotClient = newClient(timeout, true)
+	})
+	return oneShotClient
+}
 
-	req, err := http.NewRequestWithContext(ctx, "GET", target.URL, nil)
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to create request for %s: %w", target.URL, err)
+func newClient(timeout int, disableKeepAlives bool) *http.Client {
+	transport := &http.Transport{
+		Proxy:               http.ProxyFromEnvironment,
+		DialContext:         (&net.Dialer{KeepAlive: 30 * time.Second, ControlContext: checkExcluded}).DialContext,
+		MaxIdleConns:        500,
+		MaxIdleConnsPerHost: 100,
+		IdleConnTimeout:     90 * time.Second,
+		TLSHandshakeTimeout: 10 * time.Second,
+		DisableKeepAlives:   disableKeepAlives,
+	}
+	return &http.Client{
+		Transport: transport,
+		Timeout:   time.Duration(timeout) * time.Second,
 	}
-	req.Header.Set("User-Agent", "github.com/Abhaythakor/hyperwapp/1.0.0")
+}
 
-	resp, err := client.Do(req)
+// FetchOnline fetches the content of a URL and returns its headers, body and TLS state.
+func FetchOnline(ctx context.Context, target model.Target, timeout int, opts Options) (*Response, error) {
+	if opts.OneShot {
+		return Fetch(ctx, getOneShotClient(timeout), target, opts)
+	}
+	return Fetch(ctx, GetClient(timeout), target, opts)
+}
+
+// Fetch fetches a target with the given client, retrying retryable failures
+// up to opts.Retries times with exponential backoff.
+func Fetch(ctx context.Context, client *http.Client, target model.Target, opts Options) (*Response, error) {
+	if opts.Exclude != nil {
+		ctx = context.WithValue(ctx, excludeKey{}, opts.Exclude)
+	}
+	var last *Response // Last response with a retryable status
+	for attempt := 0; ; attempt++ {
+		result, err := fetchOnce(ctx, client, target, opts)
+		if err == nil && opts.Retries > 0 && retryableStatus(result.StatusCode) {
+			result.Failure = &StatusError{
+				URL:        target.URL,
+				Code:       result.StatusCode,
+				RetryAfter: parseRetryAfter(http.Header(result.Headers).Get("Retry-After"), time.Now()),
+			}
+			last, err = result, result.Failure
+		}
+		if err == nil {
+			return result, nil
+		}
+		if attempt >= opts.Retries || ctx.Err() != nil || !Retryable(err) {
+			if last != nil && ctx.Err() == nil {
+				return last, nil
+			}
+			return nil, err
+		}
+
+		delay := retryDelay(err, attempt, opts.RetryDelay)
+		util.Debug("Retrying %s in %v (%d/%d): %v", target.URL, delay.Round(time.Millisecond), attempt+1, opts.Retries, err)
+		if err := sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+		if opts.Wait != nil {
+			if err := opts.Wait(ctx, target.URL); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+func fetchOnce(ctx context.Context, client *http.Client, target model.Target, opts Options) (*Response, error) {
+	if opts.HeadersOnly {
+		return fetchHeaders(ctx, client, target, opts)
+	}
+
+	resp, hops, err := follow(ctx, client, http.MethodGet, target.URL, opts, nil)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to fetch %s: %w", target.URL, err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
-	headers := make(map[string][]string)
-	for k, v := range resp.Header {
-		headers[k] = v
+	result := newResponse(resp)
+	result.Redirects = hops
+	if opts.SkipBinary && detect.IsBinaryContentType(resp.Header.Get("Content-Type")) {
+		util.Debug("Skipping binary body of %s (%s)", target.URL, resp.Header.Get("Content-Type"))
+		return result, nil
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	var reader io.Reader = resp.Body
+	if opts.MaxBodySize > 0 {
+		// Read one byte past the limit to tell a body of exactly MaxBodySize from a longer one
+		reader = io.LimitReader(resp.Body, opts.MaxBodySize+1)
+	}
+	body, err := io.ReadAll(reader)
 	if err != nil {
 		util.Warn("Failed to read body for %s: %v", target.URL, err)
 		// Don't return error, proceed with headers if body read fails
-		return headers, nil, nil
+		return result, nil
 	}
+	if opts.MaxBodySize > 0 && int64(len(body)) > opts.MaxBodySize {
+		body = body[:opts.MaxBodySize]
+		result.Truncated = true
+		util.Debug("Body of %s truncated at %d bytes", target.URL, opts.MaxBodySize)
+	}
+	result.Body = body
+
+	return result, nil
+}
+
+// fetchHeaders retrieves only the response headers. Servers that reject HEAD
+// get a GET for the first byte whose body is closed without being read.
+func fetchHeaders(ctx context.Context, client *http.Client, target model.Target, opts Options) (*Response, error) {
+	resp, hops, err := follow(ctx, client, http.MethodHead, target.URL, opts, nil)
+	if err == nil {
+		resp.Body.Close()
+		if !headRejected(resp.StatusCode) {
+			result := newResponse(resp)
+			result.Redirects = hops
+			return result, nil
+		}
+		util.Debug("HEAD rejected by %s (%d), falling back to GET", target.URL, resp.StatusCode)
+	} else if ctx.Err() != nil || unreachable(err) {
+		return nil, err
+	} else {
+		util.Debug("HEAD failed for %s (%v), falling back to GET", target.URL, err)
+	}
+
+	resp, hops, err = follow(ctx, client, http.MethodGet, target.URL, opts, map[string]string{"Range": "bytes=0-0"})
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close() // Abort the transfer, only the headers are needed
+	result := newResponse(resp)
+	result.Redirects = hops
+	return result, nil
+}
+
+func do(ctx context.Context, client *http.Client, method, url string, request *Request, headers map[string]string, crossHost bool) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request for %s: %w", url, err)
+	}
+	request.apply(req, crossHost)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", url, err)
+	}
+	return resp, nil
+}
+
+func newResponse(resp *http.Response) *Response {
+	headers := make(map[string][]string, len(resp.Header))
+	for k, v := range resp.Header {
+		headers[k] = v
+	}
+	return &Response{URL: resp.Request.URL.String(), StatusCode: resp.StatusCode, Headers: headers, TLS: TLSInfo(resp.TLS)}
+}
+
+// headRejected reports status codes servers commonly send when they do not
+// support HEAD.
+func headRejected(status int) bool {
+	switch status {
+	case http.StatusBadRequest, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return true
+	}
+	return false
+}
+
+// unreachable reports errors a GET retry would hit as well.
+func unreachable(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
 
-	return headers, body, nil
+// TLSInfo summarizes a TLS connection state and its leaf certificate.
+func TLSInfo(state *tls.ConnectionState) *model.TLSInfo {
+	if state == nil {
+		return nil
+	}
+
+	info := &model.TLSInfo{
+		Version:            tls.VersionName(state.Version),
+		CipherSuite:        tls.CipherSuiteName(state.CipherSuite),
+		NegotiatedProtocol: state.NegotiatedProtocol,
+		ServerName:         state.ServerName,
+	}
+	if len(state.PeerCertificates) == 0 {
+		return info
+	}
+
+	cert := state.PeerCertificates[0]
+	info.SubjectCN = cert.Subject.CommonName
+	info.SubjectOrg = cert.Subject.Organization
+	info.SubjectOU = cert.Subject.OrganizationalUnit
+	info.IssuerCN = cert.Issuer.CommonName
+	info.IssuerOrg = cert.Issuer.Organization
+	info.NotBefore = cert.NotBefore.UTC()
+	info.NotAfter = cert.NotAfter.UTC()
+	info.SelfSigned = isSelfSigned(cert)
+
+	info.SANs = append(info.SANs, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		info.SANs = append(info.SANs, ip.String())
+	}
+	return info
+}
+
+func isSelfSigned(cert *x509.Certificate) bool {
+	if !bytes.Equal(cert.RawIssuer, cert.RawSubject) {
+		return false
+	}
+	return cert.CheckSignatureFrom(cert) == nil
 }
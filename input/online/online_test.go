@@ -0,0 +1,221 @@
+package online_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Abhaythakor/hyperwapp/detect"
+	"github.com/Abhaythakor/hyperwapp/input/online"
+	"github.com/Abhaythakor/hyperwapp/model"
+)
+
+func hello(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Server", "test")
+	w.Write([]byte("<html>hello</html>"))
+}
+
+func TestFetchTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(hello))
+	defer server.Close()
+
+	resp, err := online.Fetch(context.Background(), server.Client(), model.Target{URL: server.URL}, online.Options{})
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if string(resp.Body) != "<html>hello</html>" || resp.Headers["Server"][0] != "test" {
+		t.Errorf("Fetch() = %+v", resp)
+	}
+
+	info := resp.TLS
+	if info == nil {
+		t.Fatal("Fetch() over HTTPS should capture the TLS state")
+	}
+	if info.Version == "" || info.CipherSuite == "" {
+		t.Errorf("TLS version/cipher not recorded: %+v", info)
+	}
+	// httptest uses a self-signed "Acme Co" certificate for example.com and loopback
+	if len(info.IssuerOrg) == 0 || info.IssuerOrg[0] != "Acme Co" || !info.SelfSigned {
+		t.Errorf("TLS issuer = %v (self-signed %v), want Acme Co", info.IssuerOrg, info.SelfSigned)
+	}
+	if !contains(info.SANs, "example.com") || !contains(info.SANs, "127.0.0.1") {
+		t.Errorf("TLS SANs = %v, want example.com and 127.0.0.1", info.SANs)
+	}
+	if info.NotAfter.Before(info.NotBefore) {
+		t.Errorf("TLS validity %v - %v", info.NotBefore, info.NotAfter)
+	}
+}
+
+func TestFetchPlainHTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(hello))
+	defer server.Close()
+
+	resp, err := online.Fetch(context.Background(), server.Client(), model.Target{URL: server.URL}, online.Options{})
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if resp.TLS != nil {
+		t.Errorf("Fetch() over HTTP recorded TLS state: %+v", resp.TLS)
+	}
+}
+
+func TestFetchHeadersOnly(t *testing.T) {
+	var methods []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+		hello(w, r)
+	}))
+	defer server.Close()
+
+	resp, err := online.Fetch(context.Background(), server.Client(), model.Target{URL: server.URL}, online.Options{HeadersOnly: true})
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if resp.Body != nil || resp.Headers["Server"][0] != "test" {
+		t.Errorf("Fetch() = %+v, want headers without a body", resp)
+	}
+	if len(methods) != 1 || methods[0] != http.MethodHead {
+		t.Errorf("requests = %v, want a single HEAD", methods)
+	}
+}
+
+func TestFetchHeadersOnlyFallback(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.Header.Get("Range"))
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		hello(w, r)
+	}))
+	defer server.Close()
+
+	resp, err := online.Fetch(context.Background(), server.Client(), model.Target{URL: server.URL}, online.Options{HeadersOnly: true})
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || resp.Body != nil || resp.Headers["Server"][0] != "test" {
+		t.Errorf("Fetch() = %+v, want the GET headers without a body", resp)
+	}
+	want := []string{"HEAD ", "GET bytes=0-0"}
+	if len(requests) != 2 || requests[0] != want[0] || requests[1] != want[1] {
+		t.Errorf("requests = %q, want %q", requests, want)
+	}
+}
+
+func TestFetchMaxBodySize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Repeat("a", 100)))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		limit         int64
+		wantLen       int
+		wantTruncated bool
+	}{
+		{0, 100, false},
+		{100, 100, false},
+		{10, 10, true},
+	}
+	for _, tt := range tests {
+		resp, err := online.Fetch(context.Background(), server.Client(), model.Target{URL: server.URL}, online.Options{MaxBodySize: tt.limit})
+		if err != nil {
+			t.Fatalf("Fetch() error = %v", err)
+		}
+		if len(resp.Body) != tt.wantLen || resp.Truncated != tt.wantTruncated {
+			t.Errorf("limit %d: got %d bytes (truncated %v), want %d (truncated %v)", tt.limit, len(resp.Body), resp.Truncated, tt.wantLen, tt.wantTruncated)
+		}
+	}
+}
+
+func TestFetchSkipBinary(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("\x89PNG\r\n\x1a\n"))
+	}))
+	defer server.Close()
+
+	resp, err := online.Fetch(context.Background(), server.Client(), model.Target{URL: server.URL}, online.Options{SkipBinary: true})
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if resp.Body != nil || resp.Headers["Content-Type"][0] != "image/png" {
+		t.Errorf("Fetch() = %+v, want headers without the binary body", resp)
+	}
+
+	resp, _ = online.Fetch(context.Background(), server.Client(), model.Target{URL: server.URL}, online.Options{})
+	if len(resp.Body) == 0 {
+		t.Error("Fetch() without SkipBinary should download binary bodies (favicons)")
+	}
+}
+
+func TestFetchTLSDetection(t *testing.T) {
+	cert := selfSignedCert(t, pkix.Name{CommonName: "Cloudflare Inc ECC CA-3", Organization: []string{"Cloudflare, Inc."}}, []string{"sni.cloudflaressl.com", "shop.example.com"})
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(hello))
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{cert}}
+	server.StartTLS()
+	defer server.Close()
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+	target := model.Target{URL: server.URL}
+	resp, err := online.Fetch(context.Background(), client, target, online.Options{})
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	target.TLS = resp.TLS
+
+	engine := &detect.TLSEngine{}
+	detections, err := engine.DetectTarget(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(detections) != 1 || detections[0].Technology != "Cloudflare" || detections[0].Source != model.SourceTLS {
+		t.Errorf("DetectTarget() = %+v, want a single Cloudflare detection", detections)
+	}
+}
+
+func selfSignedCert(t *testing.T, subject pkix.Name, dnsNames []string) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      subject,
+		DNSNames:     dnsNames,
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func contains(values []string, want string) bool {
+	for _, v := range values {
+		if v == want {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,169 @@
+package online
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Abhaythakor/hyperwapp/model"
+	"github.com/Abhaythakor/hyperwapp/util"
+)
+
+// DefaultMaxRedirects is the number of redirects followed by default, as in net/http.
+const DefaultMaxRedirects = 10
+
+// drainLimit is the number of bytes of a skipped redirect body read so the
+// connection can be reused.
+const drainLimit = 4 << 10
+
+// RedirectPolicy controls how redirects are followed. When a redirect is not
+// followed, the redirect response itself is the final response.
+type RedirectPolicy struct {
+	Max        int  // Redirects followed per fetch (0 = none)
+	SameHost   bool // Do not follow redirects to another hostname
+	KeepBodies bool // Read redirect bodies, e.g. to run detection on each hop
+}
+
+var defaultRedirectPolicy = RedirectPolicy{Max: DefaultMaxRedirects}
+
+// Chain returns the redirect hops followed to reach the response.
+func (r *Response) Chain() []model.RedirectHop {
+	if len(r.Redirects) == 0 {
+		return nil
+	}
+	hops := make([]model.RedirectHop, len(r.Redirects))
+	for i, hop := range r.Redirects {
+		hops[i] = model.RedirectHop{URL: hop.URL, StatusCode: hop.StatusCode}
+	}
+	return hops
+}
+
+// TargetTLS returns the TLS state of the target itself: that of the first
+// HTTPS response in the chain on the target's hostname, so a redirect from
+// http:// to https:// keeps it but a redirect to another host does not lend
+// that host's certificate to the target. It is nil if there is none.
+func (r *Response) TargetTLS() *model.TLSInfo {
+	chain := append(append([]*Response{}, r.Redirects...), r)
+	origin := hostname(chain[0].URL)
+	for _, hop := range chain {
+		if hop.TLS != nil && strings.EqualFold(hostname(hop.URL), origin) {
+			return hop.TLS
+		}
+	}
+	return nil
+}
+
+// hostname returns the hostname of a URL, or "" if it cannot be parsed.
+func hostname(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
+
+// follow sends a request and follows its redirects according to opts, returning
+// the final response and the redirect responses before it. The client timeout
+// covers the whole chain, up to the final body being closed.
+func follow(ctx context.Context, client *http.Client, method, rawURL string, opts Options, headers map[string]string) (*http.Response, []*Response, error) {
+	policy := defaultRedirectPolicy
+	if opts.Redirects != nil {
+		policy = *opts.Redirects
+	}
+	cancel := context.CancelFunc(func() {})
+	if client.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, client.Timeout)
+	}
+	client = withoutRedirects(client)
+
+	var hops []*Response
+	var origin string // Hostname of the target
+	crossHost := false
+	for {
+		resp, err := do(ctx, client, method, rawURL, opts.Request, headers, crossHost)
+		if err != nil {
+			cancel()
+			return nil, nil, err
+		}
+		if origin == "" {
+			origin = resp.Request.URL.Hostname()
+		}
+		next := redirectLocation(resp)
+		if next == nil || len(hops) >= policy.Max {
+			resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+			return resp, hops, nil
+		}
+		if policy.SameHost && !strings.EqualFold(next.Hostname(), resp.Request.URL.Hostname()) {
+			util.Debug("Not following redirect from %s to another host: %s", rawURL, next)
+			resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+			return resp, hops, nil
+		}
+
+		hop := newResponse(resp)
+		if policy.KeepBodies && method != http.MethodHead {
+			hop.Body, hop.Truncated = readHopBody(resp.Body, opts.MaxBodySize)
+		} else {
+			io.Copy(io.Discard, io.LimitReader(resp.Body, drainLimit))
+		}
+		resp.Body.Close()
+		hops = append(hops, hop)
+		rawURL = next.String()
+		crossHost = !strings.EqualFold(next.Hostname(), origin)
+	}
+}
+
+// cancelBody releases the deadline of a redirect chain once its final body is closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// withoutRedirects returns a copy of client that hands redirect responses back
+// instead of following them. Its timeout is left to the caller's context, so
+// it spans every hop rather than each one.
+func withoutRedirects(client *http.Client) *http.Client {
+	c := *client
+	c.Timeout = 0
+	c.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	return &c
+}
+
+// redirectLocation returns the URL a redirect response points to, or nil if
+// the response is not a redirect that can be followed.
+func redirectLocation(resp *http.Response) *url.URL {
+	switch resp.StatusCode {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+	default:
+		return nil
+	}
+	next, err := resp.Location()
+	if err != nil || (next.Scheme != "http" && next.Scheme != "https") {
+		return nil
+	}
+	return next
+}
+
+func readHopBody(body io.Reader, maxBodySize int64) ([]byte, bool) {
+	if maxBodySize > 0 {
+		body = io.LimitReader(body, maxBodySize+1)
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, false
+	}
+	if maxBodySize > 0 && int64(len(data)) > maxBodySize {
+		return data[:maxBodySize], true
+	}
+	return data, false
+}
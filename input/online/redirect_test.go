@@ -0,0 +1,175 @@
+package online_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Abhaythakor/hyperwapp/input/online"
+	"github.com/Abhaythakor/hyperwapp/model"
+)
+
+// redirectServer serves /a -> 301 /b -> 302 http://other.test/c -> 200. Its
+// client connects to the server whatever the host of the URL.
+func redirectServer(t *testing.T) *http.Client {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/a", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Server", "gateway")
+		w.Header().Set("Location", "/b")
+		w.WriteHeader(http.StatusMovedPermanently)
+		w.Write([]byte("moved"))
+	})
+	mux.HandleFunc("/b", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://other.test/c", http.StatusFound)
+	})
+	mux.HandleFunc("/c", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("final"))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, server.Listener.Addr().String())
+		},
+	}}
+}
+
+func TestFetchRedirects(t *testing.T) {
+	client := redirectServer(t)
+	target := model.Target{URL: "http://start.test/a"}
+	fetch := func(opts online.Options) *online.Response {
+		t.Helper()
+		resp, err := online.Fetch(context.Background(), client, target, opts)
+		if err != nil {
+			t.Fatalf("Fetch() error = %v", err)
+		}
+		return resp
+	}
+
+	resp := fetch(online.Options{})
+	want := []model.RedirectHop{{URL: "http://start.test/a", StatusCode: 301}, {URL: "http://start.test/b", StatusCode: 302}}
+	chain := resp.Chain()
+	if resp.URL != "http://other.test/c" || string(resp.Body) != "final" || len(chain) != 2 || chain[0] != want[0] || chain[1] != want[1] {
+		t.Fatalf("Fetch() = %s %q via %v, want http://other.test/c via %v", resp.URL, resp.Body, chain, want)
+	}
+	if resp.Redirects[0].Headers["Server"][0] != "gateway" || resp.Redirects[0].Body != nil {
+		t.Errorf("first hop = %+v, want its headers and no body", resp.Redirects[0])
+	}
+
+	resp = fetch(online.Options{Redirects: &online.RedirectPolicy{Max: 2, KeepBodies: true}})
+	if string(resp.Redirects[0].Body) != "moved" {
+		t.Errorf("hop body = %q with KeepBodies, want %q", resp.Redirects[0].Body, "moved")
+	}
+
+	resp = fetch(online.Options{Redirects: &online.RedirectPolicy{Max: 1}})
+	if resp.URL != "http://start.test/b" || resp.StatusCode != http.StatusFound || len(resp.Redirects) != 1 {
+		t.Errorf("Max 1 stopped at %s (%d) after %d hops, want the 302 of /b after 1", resp.URL, resp.StatusCode, len(resp.Redirects))
+	}
+
+	resp = fetch(online.Options{Redirects: &online.RedirectPolicy{Max: 0}})
+	if resp.URL != target.URL || resp.StatusCode != http.StatusMovedPermanently || resp.Chain() != nil {
+		t.Errorf("Max 0 = %s (%d), want the 301 of %s", resp.URL, resp.StatusCode, target.URL)
+	}
+
+	resp = fetch(online.Options{Redirects: &online.RedirectPolicy{Max: 10, SameHost: true}})
+	if resp.URL != "http://start.test/b" || len(resp.Redirects) != 1 {
+		t.Errorf("SameHost followed to %s, want to stop at http://start.test/b", resp.URL)
+	}
+
+	resp = fetch(online.Options{HeadersOnly: true})
+	if resp.URL != "http://other.test/c" || len(resp.Redirects) != 2 {
+		t.Errorf("headers-only fetch ended at %s after %d hops, want http://other.test/c after 2", resp.URL, len(resp.Redirects))
+	}
+}
+
+func TestFetchRedirectCrossHostHeaders(t *testing.T) {
+	seen := make(map[string]http.Header)
+	servers := make(map[string]string)
+	for _, host := range []string{"a.test", "b.test"} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			seen[host+r.URL.Path] = r.Header.Clone()
+			switch r.URL.Path {
+			case "/start":
+				http.Redirect(w, r, "http://b.test/next", http.StatusFound)
+			case "/next":
+				http.Redirect(w, r, "http://a.test/back", http.StatusFound)
+			}
+		}))
+		t.Cleanup(server.Close)
+		servers[host] = server.Listener.Addr().String()
+	}
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			host, _, _ := net.SplitHostPort(addr)
+			return (&net.Dialer{}).DialContext(ctx, network, servers[host])
+		},
+	}}
+
+	request := &online.Request{
+		Headers:     http.Header{"Authorization": {"Bearer secret"}, "Cookie": {"session=abc"}, "X-Scan": {"1"}},
+		HostHeaders: map[string]http.Header{"b.test": {"X-Key": {"b"}}},
+	}
+	resp, err := online.Fetch(context.Background(), client, model.Target{URL: "http://a.test/start"}, online.Options{Request: request})
+	if err != nil || resp.URL != "http://a.test/back" {
+		t.Fatalf("Fetch() = %v, %v, want to end at http://a.test/back", resp, err)
+	}
+
+	other := seen["b.test/next"]
+	if other.Get("Authorization") != "" || other.Get("Cookie") != "" {
+		t.Errorf("credentials sent to the redirect host: %v", other)
+	}
+	if other.Get("X-Scan") != "1" || other.Get("X-Key") != "b" {
+		t.Errorf("other headers and the host's own overrides should still be sent: %v", other)
+	}
+	for _, path := range []string{"a.test/start", "a.test/back"} {
+		if seen[path].Get("Authorization") != "Bearer secret" || seen[path].Get("Cookie") != "session=abc" {
+			t.Errorf("credentials missing on the target host (%s): %v", path, seen[path])
+		}
+	}
+}
+
+func TestFetchRedirectTimeoutSpansChain(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(60 * time.Millisecond)
+		if hop, _ := strconv.Atoi(r.URL.Query().Get("hop")); hop < 3 {
+			http.Redirect(w, r, "/?hop="+strconv.Itoa(hop+1), http.StatusFound)
+		}
+	}))
+	defer server.Close()
+
+	// Every hop answers within the timeout, the chain does not
+	client := &http.Client{Timeout: 150 * time.Millisecond}
+	_, err := online.Fetch(context.Background(), client, model.Target{URL: server.URL}, online.Options{})
+	if class := online.Classify(err); class != online.ClassTimeout {
+		t.Errorf("Fetch() error = %v (%s), want a timeout", err, class)
+	}
+}
+
+func TestResponseTargetTLS(t *testing.T) {
+	target := &model.TLSInfo{IssuerCN: "R11"}
+	other := &model.TLSInfo{IssuerCN: "Cloudflare Inc ECC CA-3"}
+
+	tests := []struct {
+		name string
+		resp *online.Response
+		want *model.TLSInfo
+	}{
+		{"no redirect", &online.Response{URL: "https://a.test/", TLS: target}, target},
+		{"upgrade to https", &online.Response{URL: "https://a.test/", TLS: target, Redirects: []*online.Response{{URL: "http://a.test/"}}}, target},
+		{"to another host", &online.Response{URL: "https://b.test/", TLS: other, Redirects: []*online.Response{{URL: "https://a.test/", TLS: target}}}, target},
+		{"http to another host", &online.Response{URL: "https://b.test/", TLS: other, Redirects: []*online.Response{{URL: "http://a.test/"}}}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.resp.TargetTLS(); got != tt.want {
+				t.Errorf("TargetTLS() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package online
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultUserAgent identifies HyperWapp when no browser profile is selected.
+const DefaultUserAgent = "github.com/Abhaythakor/hyperwapp/1.0.0"
+
+// User-Agent profile names besides the browser presets.
+const (
+	ProfileDefault = "default"
+	ProfileRandom  = "random"
+)
+
+// browserAccept are the content negotiation headers sent by browser profiles.
+var browserAccept = map[string]string{
+	"Accept":          "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,*/*;q=0.8",
+	"Accept-Language": "en-US,en;q=0.9",
+}
+
+// browserProfiles are the User-Agents of the browser-like presets.
+var browserProfiles = map[string]string{
+	"chrome":  "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Safari/537.36",
+	"firefox": "Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:133.0) Gecko/20100101 Firefox/133.0",
+	"safari":  "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/18.1 Safari/605.1.15",
+	"edge":    "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Safari/537.36 Edg/131.0.0.0",
+	"android": "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Mobile Safari/537.36",
+	"iphone":  "Mozilla/5.0 (iPhone; CPU iPhone OS 18_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/18.1 Mobile/15E148 Safari/604.1",
+}
+
+// browserNames lists the browser presets in a stable order for random rotation.
+var browserNames = func() []string {
+	names := make([]string, 0, len(browserProfiles))
+	for name := range browserProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}()
+
+// ProfileNames returns the selectable User-Agent profiles.
+func ProfileNames() []string {
+	return append([]string{ProfileDefault, ProfileRandom}, browserNames...)
+}
+
+// Request customizes the headers of every online request. A nil Request sends
+// only the default User-Agent.
+type Request struct {
+	Profile     string                 // User-Agent profile (see ProfileNames)
+	Headers     http.Header            // -H and --cookie, sent to every host
+	HostHeaders map[string]http.Header // Per-host overrides: "example.com", "example.com:8443" or "*.example.com"
+}
+
+// NewRequest validates the request flags: a User-Agent profile, "Name: value"
+// headers, cookies and an optional YAML file of per-host headers.
+func NewRequest(profile string, headers, cookies []string, hostHeadersPath string) (*Request, error) {
+	if profile == "" {
+		profile = ProfileDefault
+	}
+	if _, ok := browserProfiles[profile]; !ok && profile != ProfileDefault && profile != ProfileRandom {
+		return nil, fmt.Errorf("unknown User-Agent profile %q (available: %s)", profile, strings.Join(ProfileNames(), ", "))
+	}
+
+	r := &Request{Profile: profile, Headers: make(http.Header)}
+	for _, h := range headers {
+		name, value, err := ParseHeader(h)
+		if err != nil {
+			return nil, err
+		}
+		r.Headers.Add(name, value)
+	}
+	if len(cookies) > 0 {
+		addCookies(r.Headers, strings.Join(cookies, "; "))
+	}
+
+	if hostHeadersPath != "" {
+		var err error
+		if r.HostHeaders, err = LoadHostHeaders(hostHeadersPath); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// ParseHeader splits a "Name: value" header.
+func ParseHeader(header string) (string, string, error) {
+	name, value, ok := strings.Cut(header, ":")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" || strings.ContainsAny(name, " \t") {
+		return "", "", fmt.Errorf("invalid header %q (expected \"Name: value\")", header)
+	}
+	return http.CanonicalHeaderKey(name), strings.TrimSpace(value), nil
+}
+
+// addCookies appends cookies to the Cookie header, which must be a single line.
+func addCookies(headers http.Header, cookies string) {
+	if existing := headers.Get("Cookie"); existing != "" {
+		cookies = existing + "; " + cookies
+	}
+	headers.Set("Cookie", cookies)
+}
+
+// LoadHostHeaders reads per-host headers from a YAML file mapping host
+// patterns to headers:
+//
+//	app.example.com:
+//	  Authorization: Bearer token
+//	"*.internal.example.com":
+//	  Cookie: session=abc
+func LoadHostHeaders(path string) (map[string]http.Header, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read host headers file: %w", err)
+	}
+	var raw map[string]map[string]string
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse host headers file %s: %w", path, err)
+	}
+
+	hosts := make(map[string]http.Header, len(raw))
+	for host, headers := range raw {
+		pattern := strings.ToLower(strings.TrimSpace(host))
+		if pattern == "" || pattern == "*." {
+			return nil, fmt.Errorf("invalid host pattern %q in %s", host, path)
+		}
+		h := make(http.Header, len(headers))
+		for name, value := range headers {
+			name, value, err := ParseHeader(name + ": " + value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", host, err)
+			}
+			h.Set(name, value)
+		}
+		hosts[pattern] = h
+	}
+	return hosts, nil
+}
+
+// crossHostHeaders are the global headers only sent to the host of the
+// target, not to another host it redirects to: credentials, as net/http
+// strips them, and the Host override.
+var crossHostHeaders = map[string]bool{
+	"Authorization":    true,
+	"Www-Authenticate": true,
+	"Cookie":           true,
+	"Cookie2":          true,
+	"Host":             true,
+}
+
+// apply sets the User-Agent, global headers and host overrides on req, in
+// increasing order of precedence. crossHost is set for a redirect to another
+// host than the target's, which still gets the overrides of its own host.
+func (r *Request) apply(req *http.Request, crossHost bool) {
+	if r == nil {
+		req.Header.Set("User-Agent", DefaultUserAgent)
+		return
+	}
+
+	profile := r.Profile
+	if profile == ProfileRandom {
+		profile = browserNames[rand.IntN(len(browserNames))]
+	}
+	if ua, ok := browserProfiles[profile]; ok {
+		req.Header.Set("User-Agent", ua)
+		for name, value := range browserAccept {
+			req.Header.Set(name, value)
+		}
+	} else {
+		req.Header.Set("User-Agent", DefaultUserAgent)
+	}
+
+	for name, values := range r.Headers {
+		if crossHost && crossHostHeaders[name] {
+			continue
+		}
+		req.Header[name] = values
+	}
+	if hostHeaders := r.hostHeaders(req.URL); hostHeaders != nil {
+		for name, values := range hostHeaders {
+			req.Header[name] = values
+		}
+	}
+	if host := req.Header.Get("Host"); host != "" {
+		req.Host = host // Go ignores a Host entry in req.Header
+		req.Header.Del("Host")
+	}
+}
+
+// hostHeaders returns the overrides of the most specific pattern matching u:
+// host:port, then host, then the longest "*.suffix".
+func (r *Request) hostHeaders(u *url.URL) http.Header {
+	if len(r.HostHeaders) == 0 {
+		return nil
+	}
+	host := strings.ToLower(u.Host)
+	if h, ok := r.HostHeaders[host]; ok {
+		return h
+	}
+	hostname := strings.ToLower(u.Hostname())
+	if h, ok := r.HostHeaders[hostname]; ok {
+		return h
+	}
+	if net.ParseIP(hostname) != nil {
+		return nil
+	}
+	for suffix := hostname; ; {
+		_, rest, ok := strings.Cut(suffix, ".")
+		if !ok {
+			return nil
+		}
+		if h, ok := r.HostHeaders["*."+rest]; ok {
+			return h
+		}
+		suffix = rest
+	}
+}
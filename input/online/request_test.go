@@ -0,0 +1,105 @@
+package online_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Abhaythakor/hyperwapp/input/online"
+	"github.com/Abhaythakor/hyperwapp/model"
+)
+
+// echoServer records the headers of the last request. Its client connects to
+// the server whatever the host of the URL, so host patterns can be tested.
+func echoServer(t *testing.T) (*http.Client, func() *http.Request) {
+	t.Helper()
+	var last *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		last = r
+	}))
+	t.Cleanup(server.Close)
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, server.Listener.Addr().String())
+		},
+	}}
+	return client, func() *http.Request { return last }
+}
+
+func TestNewRequestErrors(t *testing.T) {
+	if _, err := online.NewRequest("netscape", nil, nil, ""); err == nil {
+		t.Error("NewRequest() with an unknown profile should fail")
+	}
+	for _, h := range []string{"NoColon", ": value", "Bad Name: value"} {
+		if _, err := online.NewRequest("", []string{h}, nil, ""); err == nil {
+			t.Errorf("NewRequest() with header %q should fail", h)
+		}
+	}
+	if _, err := online.NewRequest("", nil, nil, filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("NewRequest() with a missing host headers file should fail")
+	}
+}
+
+func TestRequestHeaders(t *testing.T) {
+	client, last := echoServer(t)
+	fetch := func(rawURL string, request *online.Request) *http.Request {
+		t.Helper()
+		if _, err := online.Fetch(context.Background(), client, model.Target{URL: rawURL}, online.Options{Request: request}); err != nil {
+			t.Fatalf("Fetch(%s) error = %v", rawURL, err)
+		}
+		return last()
+	}
+
+	if got := fetch("http://a.com/", nil).UserAgent(); got != online.DefaultUserAgent {
+		t.Errorf("default User-Agent = %q", got)
+	}
+
+	hostFile := filepath.Join(t.TempDir(), "hosts.yaml")
+	os.WriteFile(hostFile, []byte(`
+app.example.com:
+  Authorization: Bearer app
+"*.example.com":
+  Authorization: Bearer wildcard
+  User-Agent: custom-agent
+"app.example.com:8443":
+  Host: internal.example.com
+`), 0644)
+	request, err := online.NewRequest("firefox", []string{"X-Scan: 1", "Authorization: Bearer global"}, []string{"a=1", "b=2"}, hostFile)
+	if err != nil {
+		t.Fatalf("NewRequest() error = %v", err)
+	}
+
+	r := fetch("http://other.com/", request)
+	if !strings.Contains(r.UserAgent(), "Firefox") || r.Header.Get("Accept-Language") == "" {
+		t.Errorf("firefox profile not applied: %v", r.Header)
+	}
+	if r.Header.Get("X-Scan") != "1" || r.Header.Get("Authorization") != "Bearer global" || r.Header.Get("Cookie") != "a=1; b=2" {
+		t.Errorf("global headers not sent: %v", r.Header)
+	}
+
+	if r := fetch("http://app.example.com/", request); r.Header.Get("Authorization") != "Bearer app" || !strings.Contains(r.UserAgent(), "Firefox") {
+		t.Errorf("exact host override not applied: %v", r.Header)
+	}
+	if r := fetch("http://deep.api.example.com/", request); r.Header.Get("Authorization") != "Bearer wildcard" || r.UserAgent() != "custom-agent" {
+		t.Errorf("wildcard host override not applied: %v", r.Header)
+	}
+	if r := fetch("http://app.example.com:8443/", request); r.Host != "internal.example.com" || r.Header.Get("Authorization") != "Bearer global" {
+		t.Errorf("host:port override not applied: host %s, %v", r.Host, r.Header)
+	}
+	if r := fetch("http://example.com/", request); r.Header.Get("Authorization") != "Bearer global" {
+		t.Errorf("*.example.com should not match example.com: %v", r.Header)
+	}
+
+	random, _ := online.NewRequest(online.ProfileRandom, nil, nil, "")
+	for i := 0; i < 5; i++ {
+		if ua := fetch("http://a.com/", random).UserAgent(); !strings.HasPrefix(ua, "Mozilla/5.0") {
+			t.Errorf("random profile sent %q, want a browser User-Agent", ua)
+		}
+	}
+}
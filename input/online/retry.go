@@ -0,0 +1,212 @@
+package online
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// DefaultRetryDelay is the base delay before the first retry.
+const DefaultRetryDelay = 500 * time.Millisecond
+
+// MaxRetryDelay caps backoff delays and Retry-After values.
+const MaxRetryDelay = 30 * time.Second
+
+// Failure classes.
+const (
+	ClassDNS      = "dns"
+	ClassTLS      = "tls"
+	ClassRefused  = "refused"
+	ClassReset    = "reset"
+	ClassTimeout  = "timeout"
+	ClassExcluded = "excluded"
+	ClassOther    = "other"
+	// HTTP status failures are classed as "http-<code>", e.g. http-503.
+	classHTTPPrefix = "http-"
+)
+
+// StatusError describes a retryable status (429, 502, 503) that persisted
+// after all retries. Fetch reports it in Response.Failure.
+type StatusError struct {
+	URL        string
+	Code       int
+	RetryAfter time.Duration // From the Retry-After header, 0 if absent
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s returned %d %s", e.URL, e.Code, http.StatusText(e.Code))
+}
+
+// retryableStatus reports statuses worth retrying later.
+func retryableStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable:
+		return true
+	}
+	return false
+}
+
+// Classify returns the failure class of a fetch error.
+func Classify(err error) string {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return classHTTPPrefix + strconv.Itoa(statusErr.Code)
+	}
+
+	if errors.Is(err, ErrExcluded) {
+		return ClassExcluded
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		if dnsErr.IsTimeout {
+			return ClassTimeout
+		}
+		return ClassDNS
+	}
+	if isTLSError(err) {
+		return ClassTLS
+	}
+	switch {
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return ClassRefused
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return ClassReset
+	case errors.Is(err, context.DeadlineExceeded):
+		return ClassTimeout
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return ClassTimeout
+	}
+	return ClassOther
+}
+
+func isTLSError(err error) bool {
+	var (
+		recordErr    tls.RecordHeaderError
+		alertErr     tls.AlertError
+		verifyErr    *tls.CertificateVerificationError
+		authorityErr x509.UnknownAuthorityError
+		hostnameErr  x509.HostnameError
+		invalidErr   x509.CertificateInvalidError
+	)
+	if errors.As(err, &recordErr) || errors.As(err, &alertErr) || errors.As(err, &verifyErr) ||
+		errors.As(err, &authorityErr) || errors.As(err, &hostnameErr) || errors.As(err, &invalidErr) {
+		return true
+	}
+	// Handshake failures are mostly plain errors prefixed with "tls: "
+	return strings.Contains(err.Error(), "tls: ")
+}
+
+// Retryable reports whether a failed fetch may succeed when retried:
+// timeouts, connection resets and 429/502/503 responses.
+func Retryable(err error) bool {
+	switch class := Classify(err); class {
+	case ClassTimeout, ClassReset:
+		return true
+	default:
+		return strings.HasPrefix(class, classHTTPPrefix)
+	}
+}
+
+// retryDelay returns the wait before retry number attempt (0-based): the
+// server's Retry-After if any, else an exponential backoff with jitter.
+func retryDelay(err error, attempt int, base time.Duration) time.Duration {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.RetryAfter > 0 {
+		return min(statusErr.RetryAfter, MaxRetryDelay)
+	}
+	if base <= 0 {
+		base = DefaultRetryDelay
+	}
+	delay := base << attempt
+	if delay <= 0 || delay > MaxRetryDelay {
+		delay = MaxRetryDelay
+	}
+	// Equal jitter: half fixed, half random, so workers retrying the same
+	// host do not hit it in lockstep.
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as an HTTP date.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Failures counts final fetch failures per class. It is safe for concurrent use.
+type Failures struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+// Record counts a failure and returns its class.
+func (f *Failures) Record(err error) string {
+	class := Classify(err)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.counts == nil {
+		f.counts = make(map[string]int)
+	}
+	f.counts[class]++
+	return class
+}
+
+// String lists the classes by decreasing count, e.g. "timeout 12, dns 3".
+// It is empty when nothing failed.
+func (f *Failures) String() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	classes := make([]string, 0, len(f.counts))
+	for class := range f.counts {
+		classes = append(classes, class)
+	}
+	sort.Slice(classes, func(i, j int) bool {
+		if f.counts[classes[i]] != f.counts[classes[j]] {
+			return f.counts[classes[i]] > f.counts[classes[j]]
+		}
+		return classes[i] < classes[j]
+	})
+	parts := make([]string, len(classes))
+	for i, class := range classes {
+		parts[i] = fmt.Sprintf("%s %d", class, f.counts[class])
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,183 @@
+package online_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Abhaythakor/hyperwapp/input/online"
+	"github.com/Abhaythakor/hyperwapp/model"
+)
+
+// flakyServer fails the first failures requests with fail and then answers 200.
+func flakyServer(t *testing.T, failures int32, fail func(w http.ResponseWriter)) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= failures {
+			fail(w)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func unavailable(w http.ResponseWriter) {
+	w.Header().Set("Retry-After", "0")
+	w.WriteHeader(http.StatusServiceUnavailable)
+}
+
+func TestFetchRetriesStatus(t *testing.T) {
+	opts := online.Options{Retries: 2, RetryDelay: time.Millisecond}
+
+	server, calls := flakyServer(t, 2, unavailable)
+	resp, err := online.Fetch(context.Background(), server.Client(), model.Target{URL: server.URL}, opts)
+	if err != nil || resp.StatusCode != http.StatusOK || calls.Load() != 3 {
+		t.Fatalf("Fetch() = %v, %v after %d calls, want 200 after 3", resp, err, calls.Load())
+	}
+
+	// Once retries run out the last response is still returned for detection
+	server, calls = flakyServer(t, 3, unavailable)
+	resp, err = online.Fetch(context.Background(), server.Client(), model.Target{URL: server.URL}, opts)
+	if err != nil || resp.StatusCode != http.StatusServiceUnavailable || resp.Failure == nil || calls.Load() != 3 {
+		t.Fatalf("Fetch() = %v, %v after %d calls, want the 503 response with its Failure after 3", resp, err, calls.Load())
+	}
+	if class := online.Classify(resp.Failure); class != "http-503" {
+		t.Errorf("Classify() = %q, want http-503", class)
+	}
+
+	// Without retries the response is returned as is
+	server, _ = flakyServer(t, 1, unavailable)
+	resp, err = online.Fetch(context.Background(), server.Client(), model.Target{URL: server.URL}, online.Options{})
+	if err != nil || resp.StatusCode != http.StatusServiceUnavailable || resp.Failure != nil {
+		t.Errorf("Fetch() without retries = %v, %v, want the 503 response without a Failure", resp, err)
+	}
+
+	// Other statuses are not retried
+	server, calls = flakyServer(t, 1, func(w http.ResponseWriter) { w.WriteHeader(http.StatusNotFound) })
+	resp, err = online.Fetch(context.Background(), server.Client(), model.Target{URL: server.URL}, opts)
+	if err != nil || resp.StatusCode != http.StatusNotFound || calls.Load() != 1 {
+		t.Errorf("Fetch() = %v, %v after %d calls, want the 404 response after 1", resp, err, calls.Load())
+	}
+}
+
+func TestFetchRetriesReset(t *testing.T) {
+	server, calls := flakyServer(t, 1, func(w http.ResponseWriter) {
+		conn, _, _ := w.(http.Hijacker).Hijack()
+		conn.Close()
+	})
+	var waited []string
+	opts := online.Options{
+		Retries:    1,
+		RetryDelay: time.Millisecond,
+		Wait: func(ctx context.Context, url string) error {
+			waited = append(waited, url)
+			return nil
+		},
+	}
+	resp, err := online.Fetch(context.Background(), server.Client(), model.Target{URL: server.URL}, opts)
+	if err != nil || resp.StatusCode != http.StatusOK || calls.Load() != 2 {
+		t.Fatalf("Fetch() = %v, %v after %d calls, want 200 after 2", resp, err, calls.Load())
+	}
+	if len(waited) != 1 || waited[0] != server.URL {
+		t.Errorf("Wait called for %v, want once for %s", waited, server.URL)
+	}
+}
+
+func TestFetchRetryAfterCancelled(t *testing.T) {
+	server, calls := flakyServer(t, 1, func(w http.ResponseWriter) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := online.Fetch(ctx, server.Client(), model.Target{URL: server.URL}, online.Options{Retries: 1})
+	if !errors.Is(err, context.DeadlineExceeded) || calls.Load() != 1 {
+		t.Errorf("Fetch() error = %v after %d calls, want the context error while waiting", err, calls.Load())
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Fetch() waited %v despite the cancelled context", elapsed)
+	}
+}
+
+func TestClassify(t *testing.T) {
+	ctx := context.Background()
+	fetch := func(client *http.Client, rawURL string) error {
+		t.Helper()
+		_, err := online.Fetch(ctx, client, model.Target{URL: rawURL}, online.Options{})
+		if err == nil {
+			t.Fatalf("Fetch(%s) should fail", rawURL)
+		}
+		return err
+	}
+
+	listener, _ := net.Listen("tcp", "127.0.0.1:0")
+	closedAddr := listener.Addr().String()
+	listener.Close()
+
+	tlsServer := httptest.NewTLSServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer tlsServer.Close()
+
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer slow.Close()
+
+	tests := map[string]error{
+		online.ClassRefused: fetch(http.DefaultClient, "http://"+closedAddr),
+		online.ClassTLS:     fetch(http.DefaultClient, tlsServer.URL),
+		online.ClassTimeout: fetch(&http.Client{Timeout: 50 * time.Millisecond}, slow.URL),
+		online.ClassDNS:     fmt.Errorf("failed to fetch: %w", &net.DNSError{Err: "no such host", Name: "nx.invalid", IsNotFound: true}),
+		online.ClassOther:   errors.New("unsupported protocol scheme"),
+	}
+	for want, err := range tests {
+		if got := online.Classify(err); got != want {
+			t.Errorf("Classify(%v) = %q, want %q", err, got, want)
+		}
+	}
+	if online.Retryable(tests[online.ClassRefused]) || !online.Retryable(tests[online.ClassTimeout]) {
+		t.Error("only timeouts should be retryable among these")
+	}
+
+	var failures online.Failures
+	if failures.String() != "" {
+		t.Errorf("String() with no failures = %q", failures.String())
+	}
+	failures.Record(tests[online.ClassDNS])
+	failures.Record(tests[online.ClassTimeout])
+	failures.Record(tests[online.ClassTimeout])
+	if got := failures.String(); got != "timeout 2, dns 1" {
+		t.Errorf("String() = %q, want %q", got, "timeout 2, dns 1")
+	}
+}
+
+func TestFetchExcluded(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer server.Close()
+	exclude := func(addr netip.Addr) bool { return addr.IsLoopback() }
+
+	for _, oneShot := range []bool{false, true} {
+		_, err := online.FetchOnline(context.Background(), model.Target{URL: server.URL}, 5, online.Options{OneShot: oneShot, Exclude: exclude})
+		if class := online.Classify(err); class != online.ClassExcluded {
+			t.Errorf("FetchOnline(OneShot %v) error = %v (%s), want an excluded address error", oneShot, err, class)
+		}
+	}
+
+	// The exclusion is scoped to the fetches given it
+	if _, err := online.FetchOnline(context.Background(), model.Target{URL: server.URL}, 5, online.Options{}); err != nil {
+		t.Errorf("FetchOnline() without Exclude error = %v", err)
+	}
+}
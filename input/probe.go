@@ -0,0 +1,170 @@
+package input
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/Abhaythakor/hyperwapp/model"
+)
+
+// Probe policies for bare host inputs.
+const (
+	ProbeHTTPS = "https" // HTTPS, falling back to HTTP when HTTPS is not live
+	ProbeBoth  = "both"  // HTTPS and HTTP, each scanned if live
+)
+
+// ProbeOptions controls how bare hosts, host:port and IP inputs are turned into URLs.
+type ProbeOptions struct {
+	Policy string // ProbeHTTPS (default) or ProbeBoth
+	Ports  []int  // Ports probed for inputs without a port (nil = 443 for HTTPS, 80 for HTTP)
+}
+
+// ProbePolicies lists the accepted --probe values.
+func ProbePolicies() []string {
+	return []string{ProbeHTTPS, ProbeBoth}
+}
+
+// ParsePorts parses port lists such as "80,443" or "8000-8010", deduplicating
+// the ports while keeping their order.
+func ParsePorts(specs []string) ([]int, error) {
+	var ports []int
+	seen := make(map[int]bool)
+	for _, spec := range specs {
+		for _, part := range strings.Split(spec, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			first, last, isRange := strings.Cut(part, "-")
+			from, err := parsePort(first)
+			if err != nil {
+				return nil, err
+			}
+			to := from
+			if isRange {
+				if to, err = parsePort(last); err != nil {
+					return nil, err
+				}
+				if to < from {
+					return nil, fmt.Errorf("invalid port range %q", part)
+				}
+			}
+			for port := from; port <= to; port++ {
+				if !seen[port] {
+					seen[port] = true
+					ports = append(ports, port)
+				}
+			}
+		}
+	}
+	return ports, nil
+}
+
+func parsePort(s string) (int, error) {
+	port, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || port < 1 || port > 65535 {
+		return 0, fmt.Errorf("invalid port %q", s)
+	}
+	return port, nil
+}
+
+// isBareHost reports inputs given without a scheme.
+func isBareHost(input string) bool {
+	return !strings.Contains(input, "://")
+}
+
+// expandHost turns a bare host, host:port or IP literal into the candidate
+// targets to probe, one per port (and per scheme with ProbeBoth).
+func expandHost(input string, probe ProbeOptions) ([]model.Target, error) {
+	host, port, err := splitBareHost(input)
+	if err != nil {
+		return nil, err
+	}
+
+	var ports []int
+	switch {
+	case port != 0:
+		ports = []int{port}
+	case len(probe.Ports) > 0:
+		ports = probe.Ports
+	default:
+		ports = []int{0} // Default port of each scheme
+	}
+
+	domain := strings.TrimPrefix(host, "www.")
+	var targets []model.Target
+	for _, p := range ports {
+		httpsURL, httpURL := probeURL("https", host, p), probeURL("http", host, p)
+		if probe.Policy == ProbeBoth {
+			targets = append(targets,
+				model.Target{URL: httpsURL, Domain: domain, Probe: input},
+				model.Target{URL: httpURL, Domain: domain, Probe: input})
+		} else {
+			targets = append(targets, model.Target{URL: httpsURL, Domain: domain, Probe: input, Fallback: httpURL})
+		}
+	}
+	return targets, nil
+}
+
+// splitBareHost splits "host", "host:port", "1.2.3.4", "[::1]:8080" or "::1"
+// into a lowercased host and a port (0 if absent).
+func splitBareHost(input string) (string, int, error) {
+	if strings.ContainsAny(input, "/?#@ ") {
+		return "", 0, fmt.Errorf("not a URL or host: %q", input)
+	}
+
+	host, port := input, 0
+	if h, p, err := net.SplitHostPort(input); err == nil {
+		if port, err = parsePort(p); err != nil {
+			return "", 0, err
+		}
+		host = h
+	} else if strings.HasPrefix(input, "[") && strings.HasSuffix(input, "]") {
+		host = input[1 : len(input)-1]
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+
+	if ip := net.ParseIP(host); ip != nil {
+		return ip.String(), port, nil
+	}
+	if !validHostname(host) {
+		return "", 0, fmt.Errorf("invalid host %q", host)
+	}
+	return host, port, nil
+}
+
+func validHostname(host string) bool {
+	if host == "" || len(host) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(host, ".") {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, c := range label {
+			if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// probeURL builds the URL of host on port, leaving out the default port of the scheme.
+func probeURL(scheme, host string, port int) string {
+	defaultPort := 443
+	if scheme == "http" {
+		defaultPort = 80
+	}
+	u := url.URL{Scheme: scheme, Host: host}
+	if strings.Contains(host, ":") {
+		u.Host = "[" + host + "]"
+	}
+	if port != 0 && port != defaultPort {
+		u.Host = net.JoinHostPort(host, strconv.Itoa(port))
+	}
+	return u.String()
+}
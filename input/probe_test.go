@@ -0,0 +1,73 @@
+package input_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/Abhaythakor/hyperwapp/input"
+	"github.com/Abhaythakor/hyperwapp/model"
+)
+
+func TestParsePorts(t *testing.T) {
+	ports, err := input.ParsePorts([]string{"443,80", "8000-8002", "80"})
+	if err != nil || !reflect.DeepEqual(ports, []int{443, 80, 8000, 8001, 8002}) {
+		t.Errorf("ParsePorts() = %v, %v", ports, err)
+	}
+	for _, spec := range []string{"0", "65536", "http", "90-80", "1-"} {
+		if _, err := input.ParsePorts([]string{spec}); err == nil {
+			t.Errorf("ParsePorts(%q) should fail", spec)
+		}
+	}
+}
+
+func TestResolveBareHosts(t *testing.T) {
+	resolve := func(in string, probe input.ProbeOptions) []model.Target {
+		t.Helper()
+		targets, err := input.ResolveInput(in, false, probe)
+		if err != nil {
+			t.Fatalf("ResolveInput(%q) error = %v", in, err)
+		}
+		return targets
+	}
+
+	tests := []struct {
+		in    string
+		probe input.ProbeOptions
+		want  []model.Target
+	}{
+		{"https://www.example.com/a", input.ProbeOptions{}, []model.Target{{URL: "https://www.example.com/a", Domain: "example.com"}}},
+		{"WWW.Example.com", input.ProbeOptions{}, []model.Target{
+			{URL: "https://www.example.com", Domain: "example.com", Probe: "WWW.Example.com", Fallback: "http://www.example.com"},
+		}},
+		{"10.0.0.1:8443", input.ProbeOptions{Policy: input.ProbeBoth}, []model.Target{
+			{URL: "https://10.0.0.1:8443", Domain: "10.0.0.1", Probe: "10.0.0.1:8443"},
+			{URL: "http://10.0.0.1:8443", Domain: "10.0.0.1", Probe: "10.0.0.1:8443"},
+		}},
+		{"::1", input.ProbeOptions{Ports: []int{443, 8080}}, []model.Target{
+			{URL: "https://[::1]", Domain: "::1", Probe: "::1", Fallback: "http://[::1]:443"},
+			{URL: "https://[::1]:8080", Domain: "::1", Probe: "::1", Fallback: "http://[::1]:8080"},
+		}},
+		{"[::1]:80", input.ProbeOptions{Ports: []int{443}}, []model.Target{
+			{URL: "https://[::1]:80", Domain: "::1", Probe: "[::1]:80", Fallback: "http://[::1]"},
+		}},
+	}
+	for _, tt := range tests {
+		if got := resolve(tt.in, tt.probe); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ResolveInput(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+
+	for _, in := range []string{"example.com/path", "-bad-.com", "host:99999", "a..b"} {
+		if _, err := input.ResolveInput(in, false, input.ProbeOptions{}); err == nil {
+			t.Errorf("ResolveInput(%q) should fail", in)
+		}
+	}
+
+	list := filepath.Join(t.TempDir(), "hosts.txt")
+	os.WriteFile(list, []byte("example.com\nnot a host\nhttps://a.com\n"), 0644)
+	if got := resolve(list, input.ProbeOptions{Policy: input.ProbeBoth}); len(got) != 3 {
+		t.Errorf("ResolveInput(list) = %+v, want 2 probes of example.com and https://a.com", got)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"bufio"
 	"fmt" // Added fmt package
 	"io"
+	"math"
+	"net/netip"
 	"os"
 	"strings"
 
@@ -12,12 +14,144 @@ import (
 )
 
 // ResolveInput takes an input source (file, stdin, or direct arg) and returns a slice of targets.
-func ResolveInput(input string, offlineMode bool) ([]model.Target, error) {
+// Online inputs without a scheme are expanded into the URLs to probe according to probe.
+func ResolveInput(input string, offlineMode bool, probe ProbeOptions) ([]model.Target, error) {
 	var targets []model.Target
 
+	if offlineMode {
+		err := readInput(input, true, func(line string) error {
+			target, err := normalizeTarget(line, true)
+			if err != nil {
+				return err
+			}
+			targets = append(targets, target)
+			return nil
+		})
+		return targets, err
+	}
+
+	source, err := ResolveSource(input, probe, nil)
+	if err != nil {
+		return nil, err
+	}
+	source.Each(func(target model.Target) bool {
+		targets = append(targets, target)
+		return true
+	})
+	return targets, nil
+}
+
+// Source is a resolved online input. URLs and bare hosts are expanded when
+// the input is read, CIDR and dash ranges only while the targets are
+// iterated, so a /16 never sits in memory.
+type Source struct {
+	parts   []sourcePart
+	probe   ProbeOptions
+	exclude *AddressSet
+}
+
+// sourcePart is either a run of expanded targets or an address range.
+type sourcePart struct {
+	targets []model.Target
+	input   string // Range input line, e.g. 10.0.0.0/24
+	rng     Range
+	isRange bool
+}
+
+// ResolveSource reads an online input source (file, stdin, or direct arg).
+// Targets whose address is in exclude are left out.
+func ResolveSource(input string, probe ProbeOptions, exclude *AddressSet) (*Source, error) {
+	s := &Source{probe: probe, exclude: exclude}
+	if err := readInput(input, false, s.add); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Source) add(line string) error {
+	if isRange(line) {
+		rng, err := ParseRange(line)
+		if err != nil {
+			return err
+		}
+		s.parts = append(s.parts, sourcePart{input: line, rng: rng, isRange: true})
+		return nil
+	}
+
+	targets, err := expandInput(line, false, s.probe)
+	if err != nil {
+		return err
+	}
+	if len(s.parts) == 0 || s.parts[len(s.parts)-1].isRange {
+		s.parts = append(s.parts, sourcePart{})
+	}
+	part := &s.parts[len(s.parts)-1]
+	for _, target := range targets {
+		if s.exclude.ContainsURL(target.URL) {
+			util.Debug("Excluded: %s", target.URL)
+			continue
+		}
+		part.targets = append(part.targets, target)
+	}
+	return nil
+}
+
+// Count returns the number of targets Each yields, saturating at math.MaxUint32.
+func (s *Source) Count() uint32 {
+	perAddress := uint64(max(len(s.probe.Ports), 1))
+	if s.probe.Policy == ProbeBoth {
+		perAddress *= 2
+	}
+
+	var total uint64
+	for _, part := range s.parts {
+		if part.isRange {
+			total += (part.rng.Size() - s.exclude.Overlap(part.rng)) * perAddress
+		} else {
+			total += uint64(len(part.targets))
+		}
+	}
+	return uint32(min(total, math.MaxUint32))
+}
+
+// Each calls fn for every target in input order, expanding address ranges
+// into probe targets on the fly, until fn returns false.
+func (s *Source) Each(fn func(model.Target) bool) {
+	for _, part := range s.parts {
+		if !part.isRange {
+			for _, target := range part.targets {
+				if !fn(target) {
+					return
+				}
+			}
+			continue
+		}
+
+		complete := part.rng.Each(func(addr netip.Addr) bool {
+			if s.exclude.Contains(addr) {
+				return true
+			}
+			targets, _ := expandHost(addr.String(), s.probe)
+			for _, target := range targets {
+				target.Probe = part.input
+				if !fn(target) {
+					return false
+				}
+			}
+			return true
+		})
+		if !complete {
+			return
+		}
+	}
+}
+
+// readInput calls add for the input itself or, for a file or stdin ("-"),
+// for each non-empty line. Invalid lines of a file are skipped with a warning.
+func readInput(input string, offlineMode bool, add func(string) error) error {
 	if input == "-" { // Read from stdin
 		util.Debug("Reading input from stdin")
-		return readInputFromReader(os.Stdin, offlineMode)
+		return readInputFromReader(os.Stdin, add)
 	}
 
 	fileInfo, err := os.Stat(input)
@@ -25,44 +159,49 @@ func ResolveInput(input string, offlineMode bool) ([]model.Target, error) {
 		util.Debug("Reading input from file: %s", input)
 		file, err := os.Open(input)
 		if err != nil {
-			return nil, fmt.Errorf("failed to open input file %s: %w", input, err)
+			return fmt.Errorf("failed to open input file %s: %w", input, err)
 		}
 		defer file.Close()
-		return readInputFromReader(file, offlineMode)
+		return readInputFromReader(file, add)
 	} else if input != "" { // Direct input (URL or path for offline)
 		// If it's a directory and we aren't in offline mode, this is an error for online mode
 		if err == nil && fileInfo.IsDir() && !offlineMode {
-			return nil, fmt.Errorf("input '%s' is a directory, but -offline flag was not set", input)
+			return fmt.Errorf("input '%s' is a directory, but -offline flag was not set", input)
 		}
 
 		util.Debug("Processing direct input: %s", input)
-		target, err := normalizeTarget(input, offlineMode)
-		if err != nil {
-			return nil, err
-		}
-		targets = append(targets, target)
+		return add(input)
 	}
 
-	return targets, nil
+	return nil
 }
 
-func readInputFromReader(reader io.Reader, offlineMode bool) ([]model.Target, error) {
-	var targets []model.Target
+func readInputFromReader(reader io.Reader, add func(string) error) error {
 	scanner := bufio.NewScanner(reader)
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
 		if line == "" {
 			continue
 		}
-		target, err := normalizeTarget(line, offlineMode)
-		if err != nil {
+		if err := add(line); err != nil {
 			util.Warn("Skipping invalid input line '%s': %v", line, err)
-			continue
 		}
-		targets = append(targets, target)
 	}
 	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error reading input: %w", err)
+		return fmt.Errorf("error reading input: %w", err)
 	}
-	return targets, nil
+	return nil
+}
+
+// expandInput turns one input line into its targets: the URL itself, or the
+// probe candidates of a bare host.
+func expandInput(input string, offlineMode bool, probe ProbeOptions) ([]model.Target, error) {
+	if !offlineMode && isBareHost(input) {
+		return expandHost(input, probe)
+	}
+	target, err := normalizeTarget(input, offlineMode)
+	if err != nil {
+		return nil, err
+	}
+	return []model.Target{target}, nil
 }
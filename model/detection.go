@@ -56,19 +56,48 @@ var OfflineInputPool = sync.Pool{
 	},
 }
 
+// RecordDetection is the JSONL record type of detections.
+const RecordDetection = "detection"
+
 // Detection represents a single identified technology on a target.
 type Detection struct {
 	Domain     string    `json:"domain" csv:"domain"`         // example.com
 	URL        string    `json:"url" csv:"url"`               // https://example.com
 	Technology string    `json:"technology" csv:"technology"` // React, Cloudflare, Apache
+	Version    string    `json:"version,omitempty" csv:"version"` // 6.4.2 (empty when unknown)
+	Categories []string  `json:"categories,omitempty" csv:"categories"` // CMS, Blogs
 	NucleiTags []string  `json:"nuclei_tags,omitempty" csv:"nuclei_tags,omitempty"` // wordpress, php, etc
 	Source     string    `json:"source" csv:"source"`         // wappalyzer
-	Path       string    `json:"path" csv:"path"`             // fingerprint
-	Evidence   string    `json:"evidence" csv:"evidence"`     // wappalyzergo
-	Confidence string    `json:"confidence" csv:"confidence"` // high
+	Stage      string    `json:"stage,omitempty" csv:"stage"` // header | body | header+body | tls | dns
+	Pack       string    `json:"pack,omitempty" csv:"pack"`   // builtin | user fingerprint pack name
+	Path       string    `json:"path" csv:"path"`             // fingerprint | header:server (--evidence)
+	Evidence   string    `json:"evidence" csv:"evidence"`     // wappalyzergo | matched text (--evidence)
+	Confidence string    `json:"confidence" csv:"confidence"` // low | medium | high
+	ConfidenceScore int  `json:"confidence_score" csv:"confidence_score"` // 0-100
+	FinalURL   string    `json:"final_url,omitempty" csv:"final_url"` // URL of the final response after redirects (online)
+	Redirects  []RedirectHop `json:"redirects,omitempty" csv:"redirects"` // Redirect responses followed to reach FinalURL
+	HopURL     string    `json:"hop_url,omitempty" csv:"hop_url"` // Redirect hop the technology was seen on (--detect-redirects)
+	Truncated  bool      `json:"truncated,omitempty" csv:"truncated"` // Matched on a body cut at --max-body-size
+	CPE        string    `json:"cpe,omitempty" csv:"cpe"`   // cpe:2.3:a:wordpress:wordpress:*:...
+	Vulnerabilities []Vulnerability `json:"vulnerabilities,omitempty" csv:"-"` // Matching CVEs (--vuln-db)
+	EOLStatus  string    `json:"eol_status,omitempty" csv:"eol_status"` // supported | eol | unknown
+	EOLDate    string    `json:"eol_date,omitempty" csv:"eol_date"`     // 2022-11-28 (end of support of the release cycle)
+	LatestVersion string `json:"latest_version,omitempty" csv:"latest_version"` // Latest release of the cycle, e.g. 7.4.33
 	Timestamp  time.Time `json:"timestamp" csv:"timestamp"`   // RFC3339
 }
 
+// ConfidenceBucket maps a 0-100 confidence score to its low/medium/high bucket.
+func ConfidenceBucket(score int) string {
+	switch {
+	case score >= 80:
+		return "high"
+	case score >= 50:
+		return "medium"
+	default:
+		return "low"
+	}
+}
+
 // Validate performs schema validation on an OfflineInput struct.
 func (i *OfflineInput) Validate() error {
 	if i.Domain == "" {
@@ -8,6 +8,14 @@ const (
 	SourceWappalyzer  = "wappalyzer"
 	SourceHeadersOnly = "wappalyzer-header"
 	SourceBodyOnly    = "wappalyzer-body"
+	SourceFavicon     = "favicon"
+	SourceJSLib       = "jslib"
+	SourceTLS         = "tls"
+	SourceDNS         = "dns"
+	PackBuiltin       = "builtin"
+	EOLSupported      = "supported"
+	EOLEnded          = "eol"
+	EOLUnknown        = "unknown"
 )
 
 type Meta struct {
@@ -0,0 +1,7 @@
+package model
+
+// RedirectHop is a redirect response followed while fetching an online target.
+type RedirectHop struct {
+	URL        string `json:"url"`         // URL that answered with the redirect
+	StatusCode int    `json:"status_code"` // 301 | 302 | 303 | 307 | 308
+}
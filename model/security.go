@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+// RecordSecurityHeaders is the JSONL record type of security header reports.
+const RecordSecurityHeaders = "security_headers"
+
+// Security finding severities.
+const (
+	SeverityMedium = "medium"
+	SeverityLow    = "low"
+)
+
+// SecurityReport grades the security headers of one response (--security-headers).
+type SecurityReport struct {
+	Type      string            `json:"type"` // security_headers
+	Domain    string            `json:"domain"`
+	URL       string            `json:"url"`
+	Grade     string            `json:"grade"` // A-F
+	Score     int               `json:"score"` // 0-100
+	Findings  []SecurityFinding `json:"findings"`
+	Timestamp time.Time         `json:"timestamp"`
+}
+
+// SecurityFinding is one header hygiene issue.
+type SecurityFinding struct {
+	ID       string `json:"id"`              // missing-csp, cookie-without-secure, ...
+	Severity string `json:"severity"`        // medium | low
+	Header   string `json:"header"`          // Content-Security-Policy, Set-Cookie, ...
+	Message  string `json:"message"`         // Human readable description
+	Value    string `json:"value,omitempty"` // Offending header value or cookie name
+}
+
+// ScanResult is what a worker produces for one target: its detections, the
+// target metadata online and, with --security-headers, the header report of
+// the response.
+type ScanResult struct {
+	Detections []Detection
+	Target     *TargetInfo
+	Security   *SecurityReport
+}
@@ -1,6 +1,24 @@
 package model
 
+import "time"
+
+// RecordTarget is the JSONL record type of target metadata.
+const RecordTarget = "target"
+
 type Target struct {
-	URL    string
-	Domain string
+	URL      string
+	Domain   string
+	TLS      *TLSInfo // Filled after the online fetch; nil for plain HTTP
+	Probe    string   // Bare host input the target was expanded from; only scanned if live
+	Fallback string   // URL scanned instead when URL is not live (--probe https)
+}
+
+// TargetInfo is the metadata of an online target, written once per target
+// rather than repeated on each of its detections.
+type TargetInfo struct {
+	Type      string    `json:"type"` // target
+	Domain    string    `json:"domain"`
+	URL       string    `json:"url"`
+	TLS       *TLSInfo  `json:"tls,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
 }
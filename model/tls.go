@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// TLSInfo holds the TLS connection state and leaf certificate of an online target.
+type TLSInfo struct {
+	Version            string    `json:"version"`                       // TLS 1.3
+	CipherSuite        string    `json:"cipher_suite"`                  // TLS_AES_128_GCM_SHA256
+	NegotiatedProtocol string    `json:"negotiated_protocol,omitempty"` // ALPN: h2 | http/1.1
+	ServerName         string    `json:"server_name,omitempty"`         // SNI sent by the client
+	SubjectCN          string    `json:"subject_cn,omitempty"`
+	SubjectOrg         []string  `json:"subject_org,omitempty"`
+	SubjectOU          []string  `json:"subject_ou,omitempty"`
+	IssuerCN           string    `json:"issuer_cn,omitempty"`
+	IssuerOrg          []string  `json:"issuer_org,omitempty"`
+	SANs               []string  `json:"sans,omitempty"` // DNS names and IP addresses
+	NotBefore          time.Time `json:"not_before"`
+	NotAfter           time.Time `json:"not_after"`
+	SelfSigned         bool      `json:"self_signed,omitempty"`
+}
@@ -0,0 +1,9 @@
+package model
+
+// Vulnerability is a CVE affecting a detected technology version.
+type Vulnerability struct {
+	ID       string  `json:"id"`                 // CVE-2023-12345
+	CVSS     float64 `json:"cvss,omitempty"`     // Base score (v3.x, falling back to v2)
+	Severity string  `json:"severity,omitempty"` // CRITICAL | HIGH | MEDIUM | LOW
+	Versions string  `json:"versions,omitempty"` // Affected range, e.g. ">= 6.0, < 6.4.3"
+}
@@ -52,12 +52,23 @@ func (w *CLIWriter) Write(detections []model.Detection) error {
 
 	// Default "all" mode: compact single-line format
 	targets := make(map[string][]string)
+	finalURLs := make(map[string]string)
 	for _, d := range detections {
 		key := d.URL
 		if key == "" {
 			key = d.Domain
 		}
-		targets[key] = append(targets[key], w.color.Green(d.Technology))
+		if d.FinalURL != "" && d.FinalURL != key {
+			finalURLs[key] = d.FinalURL
+		}
+		label := w.color.Green(techLabel(d))
+		if d.EOLStatus == model.EOLEnded {
+			label += w.color.Yellow(eolShortLabel(d))
+		}
+		if len(d.Vulnerabilities) > 0 {
+			label += w.color.Red(vulnCountLabel(d))
+		}
+		targets[key] = append(targets[key], label)
 	}
 
 	for target, techs := range targets {
@@ -65,9 +76,44 @@ func (w *CLIWriter) Write(detections []model.Detection) error {
 			continue
 		}
 		// news.airbnb.com [jQuery CDN, MySQL, ...]
-		fmt.Fprintf(os.Stdout, "%s [%s]\n", w.color.Cyan(target), strings.Join(techs, ", "))
+		// http://airbnb.com -> https://www.airbnb.com/ [...] when redirected
+		label := w.color.Cyan(target)
+		if final, ok := finalURLs[target]; ok {
+			label += " -> " + w.color.Cyan(final)
+		}
+		fmt.Fprintf(os.Stdout, "%s [%s]\n", label, strings.Join(techs, ", "))
+	}
+
+	return nil
+}
+
+// WriteTarget is a no-op: target metadata is only written to JSON and JSONL.
+func (w *CLIWriter) WriteTarget(model.TargetInfo) error {
+	return nil
+}
+
+// WriteSecurity prints the grade and finding IDs of a security header report,
+// e.g. "https://example.com [Security: D (40)] missing-csp, missing-hsts".
+func (w *CLIWriter) WriteSecurity(report model.SecurityReport) error {
+	grade := fmt.Sprintf("Security: %s (%d)", report.Grade, report.Score)
+	switch report.Grade {
+	case "A", "B":
+		grade = w.color.Green(grade)
+	case "C":
+		grade = w.color.Yellow(grade)
+	default:
+		grade = w.color.Red(grade)
 	}
 
+	ids := make([]string, 0, len(report.Findings))
+	seen := make(map[string]struct{}, len(report.Findings))
+	for _, f := range report.Findings {
+		if _, ok := seen[f.ID]; !ok {
+			seen[f.ID] = struct{}{}
+			ids = append(ids, f.ID)
+		}
+	}
+	fmt.Fprintf(os.Stdout, "%s [%s] %s\n", w.color.Cyan(securityTarget(report)), grade, strings.Join(ids, ", "))
 	return nil
 }
 
@@ -96,18 +142,12 @@ func (w *CLIWriter) WriteAggregated(aggregated []aggregate.AggregatedDomain) err
 		fmt.Fprintln(os.Stdout)
 
 		fmt.Fprintf(os.Stdout, "  Technologies:\n")
-		uniqueTechs := make(map[string]struct{})
-		for _, d := range agg.Detections {
-			uniqueTechs[d.Technology] = struct{}{}
-		}
-		var sortedTechs []string
-		for tech := range uniqueTechs {
-			sortedTechs = append(sortedTechs, tech)
-		}
-		sort.Strings(sortedTechs)
-
-		for _, tech := range sortedTechs {
-			fmt.Fprintf(os.Stdout, "    - %s\n", w.color.Green(tech))
+		headings, grouped := groupByCategory(agg.Detections)
+		for _, category := range headings {
+			fmt.Fprintf(os.Stdout, "    %s:\n", w.color.Yellow(category))
+			for _, tech := range grouped[category] {
+				fmt.Fprintf(os.Stdout, "      - %s\n", w.color.Green(tech))
+			}
 		}
 		fmt.Fprintln(os.Stdout)
 	}
@@ -4,6 +4,8 @@ import (
 	"bufio"
 	"encoding/csv"
 	"os"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -11,6 +13,12 @@ import (
 	"github.com/Abhaythakor/hyperwapp/model"
 )
 
+// csvHeader is the header row of detection CSV files.
+var csvHeader = []string{"domain", "url", "technology", "version", "categories", "source", "pack", "stage", "path", "evidence", "confidence", "confidence_score", "timestamp", "truncated", "cpe", "cves", "eol_status", "eol_date", "latest_version", "final_url", "redirects", "hop_url"}
+
+// securityCSVHeader is the header row of security header CSV files.
+var securityCSVHeader = []string{"domain", "url", "grade", "score", "finding", "severity", "header", "message", "value", "timestamp"}
+
 // CSVWriter implements the Writer interface for CSV output.
 type CSVWriter struct {
 	file   *os.File
@@ -18,10 +26,33 @@ type CSVWriter struct {
 	buf    *bufio.Writer
 	mode   string
 	mu     sync.Mutex
+
+	filePath   string
+	appendMode bool
+	secFile    *os.File // Security header rows (--security-headers), opened on first report
+	secWriter  *csv.Writer
+	secBuf     *bufio.Writer
 }
 
 // NewCSVWriter creates a new CSVWriter.
 func NewCSVWriter(filePath string, appendMode bool) (*CSVWriter, error) {
+	file, buf, w, err := openCSV(filePath, appendMode, csvHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CSVWriter{
+		file:       file,
+		writer:     w,
+		buf:        buf,
+		mode:       "all",
+		filePath:   filePath,
+		appendMode: appendMode,
+	}, nil
+}
+
+// openCSV opens a CSV file, writing the header unless an existing file is appended to.
+func openCSV(filePath string, appendMode bool, header []string) (*os.File, *bufio.Writer, *csv.Writer, error) {
 	flags := os.O_CREATE | os.O_WRONLY
 	isNew := true
 	if appendMode {
@@ -35,7 +66,7 @@ func NewCSVWriter(filePath string, appendMode bool) (*CSVWriter, error) {
 
 	file, err := os.OpenFile(filePath, flags, 0644)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
 
 	buf := bufio.NewWriterSize(file, 4*1024*1024) // 4MB buffer
@@ -43,20 +74,13 @@ func NewCSVWriter(filePath string, appendMode bool) (*CSVWriter, error) {
 	
 	// Write header only if new file
 	if isNew {
-		header := []string{"domain", "url", "technology", "source", "path", "evidence", "confidence", "timestamp"}
 		if err := w.Write(header); err != nil {
 			file.Close()
-			return nil, err
+			return nil, nil, nil, err
 		}
 		w.Flush()
 	}
-
-	return &CSVWriter{
-		file:   file,
-		writer: w,
-		buf:    buf,
-		mode:   "all",
-	}, nil
+	return file, buf, w, nil
 }
 
 // Write outputs detections for individual targets to the CSV file.
@@ -73,11 +97,25 @@ func (w *CSVWriter) Write(detections []model.Detection) error {
 			d.Domain,
 			url,
 			d.Technology,
+			d.Version,
+			strings.Join(d.Categories, ";"),
 			d.Source,
+			d.Pack,
+			d.Stage,
 			d.Path,
 			d.Evidence,
 			d.Confidence,
+			strconv.Itoa(d.ConfidenceScore),
 			d.Timestamp.Format(time.RFC3339),
+			strconv.FormatBool(d.Truncated),
+			d.CPE,
+			strings.Join(vulnIDs(d), ";"),
+			d.EOLStatus,
+			d.EOLDate,
+			d.LatestVersion,
+			d.FinalURL,
+			redirectHops(d),
+			d.HopURL,
 		}
 		if err := w.writer.Write(record); err != nil {
 			return err
@@ -104,11 +142,25 @@ func (w *CSVWriter) WriteAggregated(aggregated []aggregate.AggregatedDomain) err
 				agg.Domain, // Use aggregated domain
 				d.URL,      // Keep original URL from detection
 				d.Technology,
+				d.Version,
+				strings.Join(d.Categories, ";"),
 				d.Source,
+				d.Pack,
+				d.Stage,
 				d.Path,
 				d.Evidence,
 				d.Confidence,
+				strconv.Itoa(d.ConfidenceScore),
 				d.Timestamp.Format(time.RFC3339),
+				strconv.FormatBool(d.Truncated),
+				d.CPE,
+				strings.Join(vulnIDs(d), ";"),
+				d.EOLStatus,
+				d.EOLDate,
+				d.LatestVersion,
+				d.FinalURL,
+				redirectHops(d),
+				d.HopURL,
 			}
 			if err := w.writer.Write(record); err != nil {
 				return err
@@ -119,6 +171,50 @@ func (w *CSVWriter) WriteAggregated(aggregated []aggregate.AggregatedDomain) err
 	return w.writer.Error()
 }
 
+// WriteTarget is a no-op: target metadata is only written to JSON and JSONL.
+func (w *CSVWriter) WriteTarget(model.TargetInfo) error {
+	return nil
+}
+
+// WriteSecurity writes one row per finding of a security header report to a
+// separate file next to the detections, e.g. results-security.csv. Reports
+// without findings get a single row with the grade.
+func (w *CSVWriter) WriteSecurity(report model.SecurityReport) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.secFile == nil {
+		var err error
+		w.secFile, w.secBuf, w.secWriter, err = openCSV(securityCSVPath(w.filePath), w.appendMode, securityCSVHeader)
+		if err != nil {
+			return err
+		}
+	}
+
+	findings := report.Findings
+	if len(findings) == 0 {
+		findings = []model.SecurityFinding{{}}
+	}
+	for _, f := range findings {
+		record := []string{
+			report.Domain,
+			report.URL,
+			report.Grade,
+			strconv.Itoa(report.Score),
+			f.ID,
+			f.Severity,
+			f.Header,
+			f.Message,
+			f.Value,
+			report.Timestamp.Format(time.RFC3339),
+		}
+		if err := w.secWriter.Write(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Close flushes any buffered data and closes the underlying file.
 func (w *CSVWriter) Close() {
 	w.mu.Lock()
@@ -126,4 +222,9 @@ func (w *CSVWriter) Close() {
 	w.writer.Flush()
 	w.buf.Flush()
 	w.file.Close()
+	if w.secFile != nil {
+		w.secWriter.Flush()
+		w.secBuf.Flush()
+		w.secFile.Close()
+	}
 }
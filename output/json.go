@@ -20,6 +20,8 @@ type JSONWriter struct {
 	written   bool   // To prevent double writing in Close
 	Mode      string // all | domain
 	mu        sync.Mutex
+	targets   spool[model.TargetInfo]     // Target metadata, written after the results
+	security  spool[model.SecurityReport] // Security header reports, written after the results
 }
 
 // NewJSONWriter creates a new JSONWriter.
@@ -53,6 +55,20 @@ func (w *JSONWriter) Write(detections []model.Detection) error {
 	return nil
 }
 
+// WriteTarget spools the metadata of a target for the "targets" section.
+func (w *JSONWriter) WriteTarget(info model.TargetInfo) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.targets.add(info)
+}
+
+// WriteSecurity spools a security header report for the "security_headers" section.
+func (w *JSONWriter) WriteSecurity(report model.SecurityReport) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.security.add(report)
+}
+
 // SetMode sets the output mode (all | domain).
 func (w *JSONWriter) SetMode(mode string) {
 	w.Mode = mode
@@ -92,6 +108,8 @@ func (w *JSONWriter) WriteAggregated(aggregated []aggregate.AggregatedDomain) er
 func (w *JSONWriter) Close() {
 	if w.written {
 		os.Remove(w.tempFile.Name())
+		w.targets.drain(func(model.TargetInfo) {})
+		w.security.drain(func(model.SecurityReport) {})
 		return
 	}
 	w.written = true
@@ -169,7 +187,10 @@ func (w *JSONWriter) finalizeAllMode(finalFile *os.File) {
 	if currentURL != "" {
 		writeResult(currentURL, currentDetections)
 	}
-	fmt.Fprintf(finalFile, "\n  ]\n}\n")
+	fmt.Fprintf(finalFile, "\n  ]")
+	w.writeTargetsSection(finalFile)
+	w.writeSecuritySection(finalFile)
+	fmt.Fprintf(finalFile, "\n}\n")
 }
 
 func (w *JSONWriter) finalizeDomainMode(finalFile *os.File) {
@@ -232,5 +253,44 @@ func (w *JSONWriter) finalizeDomainMode(finalFile *os.File) {
 		resBytes, _ := json.MarshalIndent(agg, "    ", "  ")
 		finalFile.Write(resBytes)
 	}
-	fmt.Fprintf(finalFile, "\n  ]\n}\n")
+	fmt.Fprintf(finalFile, "\n  ]")
+	w.writeTargetsSection(finalFile)
+	w.writeSecuritySection(finalFile)
+	fmt.Fprintf(finalFile, "\n}\n")
+}
+
+// writeTargetsSection appends the "targets" array when target metadata was spooled.
+func (w *JSONWriter) writeTargetsSection(finalFile *os.File) {
+	first := true
+	w.targets.drain(func(info model.TargetInfo) {
+		if first {
+			fmt.Fprintf(finalFile, ",\n  \"targets\": [\n")
+		} else {
+			fmt.Fprintf(finalFile, ",\n")
+		}
+		first = false
+		resBytes, _ := json.MarshalIndent(info, "    ", "  ")
+		finalFile.Write(resBytes)
+	})
+	if !first {
+		fmt.Fprintf(finalFile, "\n  ]")
+	}
+}
+
+// writeSecuritySection appends the "security_headers" array when reports were spooled.
+func (w *JSONWriter) writeSecuritySection(finalFile *os.File) {
+	first := true
+	w.security.drain(func(report model.SecurityReport) {
+		if first {
+			fmt.Fprintf(finalFile, ",\n  \"security_headers\": [\n")
+		} else {
+			fmt.Fprintf(finalFile, ",\n")
+		}
+		first = false
+		resBytes, _ := json.MarshalIndent(report, "    ", "  ")
+		finalFile.Write(resBytes)
+	})
+	if !first {
+		fmt.Fprintf(finalFile, "\n  ]")
+	}
 }
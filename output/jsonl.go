@@ -42,18 +42,39 @@ func NewJSONLWriter(filePath string, appendMode bool) (*JSONLWriter, error) {
 	}, nil
 }
 
+// jsonlDetection tags a detection record with its type so it can be told
+// apart from the other records of the stream.
+type jsonlDetection struct {
+	Type string `json:"type"` // detection
+	model.Detection
+}
+
 func (w *JSONLWriter) Write(detections []model.Detection) error {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
 	for _, d := range detections {
-		if err := w.encoder.Encode(d); err != nil {
+		if err := w.encoder.Encode(jsonlDetection{Type: model.RecordDetection, Detection: d}); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+// WriteTarget writes the metadata of a target as a "target" record.
+func (w *JSONLWriter) WriteTarget(info model.TargetInfo) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.encoder.Encode(info)
+}
+
+// WriteSecurity writes a security header report as a "security_headers" record.
+func (w *JSONLWriter) WriteSecurity(report model.SecurityReport) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.encoder.Encode(report)
+}
+
 func (w *JSONLWriter) SetMode(mode string) {
 	w.mode = mode
 }
@@ -0,0 +1,33 @@
+package output
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/Abhaythakor/hyperwapp/model"
+)
+
+// securityTarget returns the URL of a report, or its domain when unknown.
+func securityTarget(report model.SecurityReport) string {
+	if report.URL != "" {
+		return report.URL
+	}
+	return report.Domain
+}
+
+// findingLabel renders a finding, e.g. "Cookie has no SameSite attribute (cookie-without-samesite): session".
+func findingLabel(f model.SecurityFinding) string {
+	label := fmt.Sprintf("%s (%s)", f.Message, f.ID)
+	if f.Value != "" {
+		label += ": " + f.Value
+	}
+	return label
+}
+
+// securityCSVPath returns the file receiving the security header rows of a
+// CSV report, e.g. results-security.csv for results.csv.
+func securityCSVPath(filePath string) string {
+	ext := filepath.Ext(filePath)
+	return strings.TrimSuffix(filePath, ext) + "-security" + ext
+}
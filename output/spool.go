@@ -0,0 +1,43 @@
+package output
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// spool keeps records such as security header reports on disk until a writer
+// that prints them in their own section closes.
+type spool[T any] struct {
+	file *os.File
+}
+
+func (s *spool[T]) add(record T) error {
+	if s.file == nil {
+		var err error
+		s.file, err = os.CreateTemp("", "HyperWapp-spool-*.jsonl")
+		if err != nil {
+			return err
+		}
+	}
+	return json.NewEncoder(s.file).Encode(record)
+}
+
+// drain calls fn for every spooled record and removes the spool file.
+// Nothing happens when no record was added.
+func (s *spool[T]) drain(fn func(T)) {
+	if s.file == nil {
+		return
+	}
+	defer os.Remove(s.file.Name())
+	defer s.file.Close()
+
+	s.file.Seek(0, 0)
+	decoder := json.NewDecoder(s.file)
+	for {
+		var record T
+		if err := decoder.Decode(&record); err != nil {
+			break
+		}
+		fn(record)
+	}
+}
@@ -21,6 +21,7 @@ type TXTWriter struct {
 	buf      *bufio.Writer
 	mode     string
 	tempFile *os.File
+	security spool[model.SecurityReport] // Security header reports, written in their own section on Close
 }
 
 // NewTXTWriter creates a new TXTWriter.
@@ -83,9 +84,12 @@ func (w *TXTWriter) Write(detections []model.Detection) error {
 		builder := strings.Builder{}
 		builder.WriteString(fmt.Sprintf("URL: %s\n", target))
 		builder.WriteString(fmt.Sprintf("Domain: %s\n", domain))
+		if chain := redirectLabel(targetDetections[0]); chain != "" {
+			builder.WriteString(fmt.Sprintf("Redirects: %s\n", chain))
+		}
 		builder.WriteString("  Technologies:\n")
 		for _, d := range targetDetections {
-			builder.WriteString(fmt.Sprintf("    - %s (Source: %s, Confidence: %s)\n", d.Technology, d.Source, d.Confidence))
+			builder.WriteString(fmt.Sprintf("    - %s%s (Source: %s, Confidence: %s%s%s%s%s%s)\n", techLabel(d), categoryLabel(d), sourceLabel(d), confidenceLabel(d), hopLabel(d), truncatedLabel(d), eolLabel(d), vulnLabel(d), evidenceLabel(d)))
 		}
 		builder.WriteString("\n")
 
@@ -97,6 +101,18 @@ func (w *TXTWriter) Write(detections []model.Detection) error {
 	return nil
 }
 
+// WriteTarget is a no-op: target metadata is only written to JSON and JSONL.
+func (w *TXTWriter) WriteTarget(model.TargetInfo) error {
+	return nil
+}
+
+// WriteSecurity spools a security header report for the section written on Close.
+func (w *TXTWriter) WriteSecurity(report model.SecurityReport) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.security.add(report)
+}
+
 // SetMode updates the output mode.
 func (w *TXTWriter) SetMode(mode string) {
 	w.mu.Lock()
@@ -125,18 +141,12 @@ func (w *TXTWriter) WriteAggregated(aggregated []aggregate.AggregatedDomain) err
 			builder.WriteString(fmt.Sprintf("    - (and %d more URLs...)\n", len(agg.URLs)-1))
 		}
 		builder.WriteString("  Technologies:\n")
-		uniqueTechs := make(map[string]struct{})
-		for _, d := range agg.Detections {
-			uniqueTechs[d.Technology] = struct{}{}
-		}
-		var sortedTechs []string
-		for tech := range uniqueTechs {
-			sortedTechs = append(sortedTechs, tech)
-		}
-		sort.Strings(sortedTechs)
-
-		for _, tech := range sortedTechs {
-			builder.WriteString(fmt.Sprintf("    - %s\n", tech))
+		headings, grouped := groupByCategory(agg.Detections)
+		for _, category := range headings {
+			builder.WriteString(fmt.Sprintf("    %s:\n", category))
+			for _, tech := range grouped[category] {
+				builder.WriteString(fmt.Sprintf("      - %s\n", tech))
+			}
 		}
 		builder.WriteString("\n")
 
@@ -201,17 +211,12 @@ func (w *TXTWriter) Close() {
 				builder.WriteString(fmt.Sprintf("    - (and %d more URLs...)\n", len(agg.URLs)-1))
 			}
 			builder.WriteString("  Technologies:\n")
-			uniqueTechs := make(map[string]struct{})
-			for _, det := range agg.Detections {
-				uniqueTechs[det.Technology] = struct{}{}
-			}
-			var sortedTechs []string
-			for tech := range uniqueTechs {
-				sortedTechs = append(sortedTechs, tech)
-			}
-			sort.Strings(sortedTechs)
-			for _, tech := range sortedTechs {
-				builder.WriteString(fmt.Sprintf("    - %s\n", tech))
+			headings, grouped := groupByCategory(agg.Detections)
+			for _, category := range headings {
+				builder.WriteString(fmt.Sprintf("    %s:\n", category))
+				for _, tech := range grouped[category] {
+					builder.WriteString(fmt.Sprintf("      - %s\n", tech))
+				}
 			}
 			builder.WriteString("\n")
 			_, _ = w.buf.WriteString(builder.String())
@@ -219,9 +224,33 @@ func (w *TXTWriter) Close() {
 	}
 
 	if w.buf != nil {
+		w.writeSecuritySection()
 		w.buf.Flush()
 	}
 	if w.file != nil {
 		w.file.Close()
 	}
 }
+
+// writeSecuritySection appends the spooled security header reports.
+func (w *TXTWriter) writeSecuritySection() {
+	first := true
+	w.security.drain(func(report model.SecurityReport) {
+		builder := strings.Builder{}
+		if first {
+			builder.WriteString("Security Headers\n================\n\n")
+			first = false
+		}
+		builder.WriteString(fmt.Sprintf("URL: %s\n", securityTarget(report)))
+		builder.WriteString(fmt.Sprintf("Domain: %s\n", report.Domain))
+		builder.WriteString(fmt.Sprintf("  Grade: %s (%d)\n", report.Grade, report.Score))
+		if len(report.Findings) > 0 {
+			builder.WriteString("  Findings:\n")
+			for _, f := range report.Findings {
+				builder.WriteString(fmt.Sprintf("    - [%s] %s\n", f.Severity, findingLabel(f)))
+			}
+		}
+		builder.WriteString("\n")
+		_, _ = w.buf.WriteString(builder.String())
+	})
+}
@@ -1,6 +1,10 @@
 package output
 
 import (
+	"fmt"
+	"sort"
+	"strings"
+
 	"github.com/Abhaythakor/hyperwapp/aggregate" // Added aggregate package import
 	"github.com/Abhaythakor/hyperwapp/model"
 )
@@ -11,8 +15,221 @@ type Writer interface {
 	Write(detections []model.Detection) error
 	// WriteAggregated outputs detections grouped by domain.
 	WriteAggregated(aggregated []aggregate.AggregatedDomain) error
+	// WriteTarget outputs the metadata of one online target, such as its TLS state.
+	WriteTarget(info model.TargetInfo) error
+	// WriteSecurity outputs the security header report of one target.
+	WriteSecurity(report model.SecurityReport) error
 	// SetMode sets the output mode (all | domain).
 	SetMode(mode string)
 	// Close finalizes and closes the writer.
 	Close()
 }
+
+// techLabel renders a technology name followed by its version, if known.
+func techLabel(d model.Detection) string {
+	if d.Version == "" {
+		return d.Technology
+	}
+	return d.Technology + " " + d.Version
+}
+
+// categoryLabel renders the categories of a detection as a " [CMS, Blogs]" suffix.
+func categoryLabel(d model.Detection) string {
+	if len(d.Categories) == 0 {
+		return ""
+	}
+	return " [" + strings.Join(d.Categories, ", ") + "]"
+}
+
+// sourceLabel renders the detection source, naming the fingerprint pack for
+// technologies defined by a user pack, e.g. "wappalyzer, pack: internal".
+func sourceLabel(d model.Detection) string {
+	if d.Pack == "" || d.Pack == model.PackBuiltin {
+		return d.Source
+	}
+	return d.Source + ", pack: " + d.Pack
+}
+
+// confidenceLabel renders the confidence bucket followed by the numeric score, e.g. "high (90)".
+func confidenceLabel(d model.Detection) string {
+	if d.ConfidenceScore == 0 {
+		return d.Confidence
+	}
+	return fmt.Sprintf("%s (%d)", d.Confidence, d.ConfidenceScore)
+}
+
+// truncatedLabel flags detections made on a body cut at --max-body-size.
+func truncatedLabel(d model.Detection) string {
+	if !d.Truncated {
+		return ""
+	}
+	return ", body truncated"
+}
+
+// maxListedVulns is the number of CVEs listed per detection in text formats.
+const maxListedVulns = 3
+
+// vulnIDs returns the CVE IDs attached to a detection.
+func vulnIDs(d model.Detection) []string {
+	ids := make([]string, 0, len(d.Vulnerabilities))
+	for _, v := range d.Vulnerabilities {
+		ids = append(ids, v.ID)
+	}
+	return ids
+}
+
+// vulnLabel renders the most severe CVEs of a detection, e.g.
+// ", CVEs: CVE-2023-1234 (9.8), CVE-2022-5678 (7.5) +2 more".
+func vulnLabel(d model.Detection) string {
+	if len(d.Vulnerabilities) == 0 {
+		return ""
+	}
+	var parts []string
+	for i, v := range d.Vulnerabilities {
+		if i == maxListedVulns {
+			break
+		}
+		parts = append(parts, fmt.Sprintf("%s (%.1f)", v.ID, v.CVSS))
+	}
+	label := ", CVEs: " + strings.Join(parts, ", ")
+	if extra := len(d.Vulnerabilities) - maxListedVulns; extra > 0 {
+		label += fmt.Sprintf(" +%d more", extra)
+	}
+	return label
+}
+
+// vulnCountLabel renders a short CVE count suffix, e.g. " (3 CVEs, max 9.8)".
+func vulnCountLabel(d model.Detection) string {
+	if len(d.Vulnerabilities) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (%d CVEs, max %.1f)", len(d.Vulnerabilities), d.Vulnerabilities[0].CVSS)
+}
+
+// eolLabel renders the support status of a detection, e.g.
+// ", EOL since 2022-11-28, latest 7.4.33" or ", supported until 2027-12-31".
+func eolLabel(d model.Detection) string {
+	var label string
+	switch d.EOLStatus {
+	case model.EOLEnded:
+		label = ", EOL"
+		if d.EOLDate != "" {
+			label += " since " + d.EOLDate
+		}
+	case model.EOLSupported:
+		label = ", supported"
+		if d.EOLDate != "" {
+			label += " until " + d.EOLDate
+		}
+	default:
+		return ""
+	}
+	if d.LatestVersion != "" && d.LatestVersion != d.Version {
+		label += ", latest " + d.LatestVersion
+	}
+	return label
+}
+
+// redirectLabel renders the redirect chain of a target with the status of each
+// hop, e.g. "301 http://example.com/ -> 302 https://example.com/ -> https://example.com/login".
+func redirectLabel(d model.Detection) string {
+	if len(d.Redirects) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	for _, hop := range d.Redirects {
+		fmt.Fprintf(&b, "%d %s -> ", hop.StatusCode, hop.URL)
+	}
+	b.WriteString(d.FinalURL)
+	return b.String()
+}
+
+// redirectHops renders the redirect hops for a CSV cell, e.g.
+// "301 http://example.com/;302 https://example.com/".
+func redirectHops(d model.Detection) string {
+	hops := make([]string, len(d.Redirects))
+	for i, hop := range d.Redirects {
+		hops[i] = fmt.Sprintf("%d %s", hop.StatusCode, hop.URL)
+	}
+	return strings.Join(hops, ";")
+}
+
+// hopLabel names the redirect hop a technology was detected on (--detect-redirects).
+func hopLabel(d model.Detection) string {
+	if d.HopURL == "" {
+		return ""
+	}
+	return ", on redirect " + d.HopURL
+}
+
+// eolShortLabel flags end-of-life versions in compact listings, e.g. " (EOL 2022-11-28)".
+func eolShortLabel(d model.Detection) string {
+	if d.EOLStatus != model.EOLEnded {
+		return ""
+	}
+	if d.EOLDate == "" {
+		return " (EOL)"
+	}
+	return " (EOL " + d.EOLDate + ")"
+}
+
+// hasEvidence reports whether a detection carries a real match location (--evidence).
+func hasEvidence(d model.Detection) bool {
+	return d.Path != "" && d.Path != "fingerprint"
+}
+
+// evidenceLabel renders the match location of a detection as an ", Evidence: ..." suffix.
+func evidenceLabel(d model.Detection) string {
+	if !hasEvidence(d) {
+		return ""
+	}
+	return fmt.Sprintf(", Stage: %s, Evidence: %s = %q", d.Stage, d.Path, d.Evidence)
+}
+
+// mdEvidenceLabel is the Markdown variant of evidenceLabel.
+func mdEvidenceLabel(d model.Detection) string {
+	if !hasEvidence(d) {
+		return ""
+	}
+	return fmt.Sprintf(", Stage: `%s`, Evidence: `%s` = `%s`", d.Stage, d.Path, strings.ReplaceAll(d.Evidence, "`", "'"))
+}
+
+// otherCategory is the heading for technologies without a Wappalyzer category.
+const otherCategory = "Other"
+
+// groupByCategory groups the unique technology labels of a domain under their
+// category headings. A technology in several categories is listed under each.
+// Headings are sorted alphabetically with "Other" last; labels are sorted too.
+func groupByCategory(detections []model.Detection) ([]string, map[string][]string) {
+	unique := make(map[string]map[string]struct{})
+	for _, d := range detections {
+		categories := d.Categories
+		if len(categories) == 0 {
+			categories = []string{otherCategory}
+		}
+		label := techLabel(d) + eolShortLabel(d) + vulnCountLabel(d)
+		for _, c := range categories {
+			if _, ok := unique[c]; !ok {
+				unique[c] = make(map[string]struct{})
+			}
+			unique[c][label] = struct{}{}
+		}
+	}
+
+	headings := make([]string, 0, len(unique))
+	grouped := make(map[string][]string, len(unique))
+	for c, labels := range unique {
+		headings = append(headings, c)
+		for label := range labels {
+			grouped[c] = append(grouped[c], label)
+		}
+		sort.Strings(grouped[c])
+	}
+	sort.Slice(headings, func(i, j int) bool {
+		if headings[i] == otherCategory || headings[j] == otherCategory {
+			return headings[j] == otherCategory && headings[i] != otherCategory
+		}
+		return headings[i] < headings[j]
+	})
+	return headings, grouped
+}
@@ -25,6 +25,14 @@ type Tracker struct {
 	stopChan   chan struct{}
 	isLogMode  bool // True for Termux or non-interactive terminals
 	lastLog    time.Time
+	summaries  []summaryLine
+	rateInfo   func() string // Extra progress line segment, e.g. the effective request rate
+}
+
+// summaryLine is an extra line printed below the final summary.
+type summaryLine struct {
+	label  string
+	render func() string
 }
 
 // NewTracker creates a new progress tracker.
@@ -146,6 +154,24 @@ func (t *Tracker) Done() {
 	// Final summary
 	fmt.Fprintf(os.Stderr, "[+] Scan Finished: %d targets in %s (S:%d, E:%d)\n",
 		completed, elapsed, success, errors)
+	for _, line := range t.summaries {
+		if value := line.render(); value != "" {
+			fmt.Fprintf(os.Stderr, "[+] %s: %s\n", line.label, value)
+		}
+	}
+}
+
+// AddSummary registers an extra line for the final summary, e.g. cache counters.
+// render is called once, when the scan finishes; the line is skipped if it
+// returns an empty string.
+func (t *Tracker) AddSummary(label string, render func() string) {
+	t.summaries = append(t.summaries, summaryLine{label: label, render: render})
+}
+
+// SetRateInfo adds a segment rendered on every refresh to the progress line,
+// such as the effective request rate under --rate-limit.
+func (t *Tracker) SetRateInfo(render func() string) {
+	t.rateInfo = render
 }
 
 // Clear clears the progress line completely.
@@ -204,6 +230,9 @@ func (t *Tracker) printProgress(force bool) {
 				rps,
 				elapsed.Round(time.Second))
 		}
+		if t.rateInfo != nil {
+			progressLine += " | " + t.rateInfo()
+		}
 	}
 
 	// ALWAYS use \r\033[2K to update in-place. 
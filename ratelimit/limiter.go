@@ -0,0 +1,187 @@
+// Package ratelimit spaces online requests globally and per host.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// rateWindow is the period over which the effective rate is measured.
+const rateWindow = 5 * time.Second
+
+// pruneEvery is the number of reservations between removals of idle hosts.
+const pruneEvery = 10000
+
+// Limiter enforces a minimum interval between any two requests and between
+// two requests to the same host. It is safe for concurrent use.
+type Limiter struct {
+	rate        float64
+	perHostRate float64
+	global      time.Duration // Interval between any two requests (0 = unlimited)
+	perHost     time.Duration // Interval between two requests to one host (0 = unlimited)
+
+	mu         sync.Mutex
+	nextGlobal time.Time
+	hosts      map[string]time.Time // Host -> earliest time of its next request
+	reserved   int
+
+	sent     atomic.Uint64
+	statusMu sync.Mutex
+	samples  []sample
+}
+
+type sample struct {
+	at   time.Time
+	sent uint64
+}
+
+// New creates a Limiter for the given requests per second, globally and per
+// host. It returns nil when both are 0, meaning no limit.
+func New(rate, perHostRate float64) *Limiter {
+	if rate <= 0 && perHostRate <= 0 {
+		return nil
+	}
+	return &Limiter{
+		rate:        rate,
+		perHostRate: perHostRate,
+		global:      interval(rate),
+		perHost:     interval(perHostRate),
+		hosts:       make(map[string]time.Time),
+	}
+}
+
+func interval(rate float64) time.Duration {
+	if rate <= 0 {
+		return 0
+	}
+	return time.Duration(float64(time.Second) / rate)
+}
+
+// Host returns the key a URL is limited under: its lowercased hostname, so
+// every port of a server shares one budget.
+func Host(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+// delay returns how long a request to host must wait from now.
+func (l *Limiter) delay(host string, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.delayLocked(host, now)
+}
+
+func (l *Limiter) delayLocked(host string, now time.Time) time.Duration {
+	ready := l.nextGlobal
+	if next := l.hosts[host]; next.After(ready) {
+		ready = next
+	}
+	return ready.Sub(now)
+}
+
+// reserveLocked records a request to host sent at now.
+func (l *Limiter) reserveLocked(host string, now time.Time) {
+	if l.global > 0 {
+		l.nextGlobal = later(l.nextGlobal, now).Add(l.global)
+	}
+	if l.perHost > 0 {
+		l.hosts[host] = later(l.hosts[host], now).Add(l.perHost)
+	}
+	l.sent.Add(1)
+
+	// Hosts whose next slot has passed behave like unseen hosts, drop them
+	// so scans over millions of hosts do not keep one entry per host.
+	l.reserved++
+	if l.reserved%pruneEvery == 0 {
+		for h, next := range l.hosts {
+			if !next.After(now) {
+				delete(l.hosts, h)
+			}
+		}
+	}
+}
+
+func later(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+// Wait blocks until a request to the host of rawURL is allowed and reserves
+// it. It is called before every request: for the targets handed out by
+// Schedule and for the requests made while handling them, such as favicons.
+func (l *Limiter) Wait(ctx context.Context, rawURL string) error {
+	if l == nil {
+		return nil
+	}
+	host := Host(rawURL)
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		wait := l.delayLocked(host, now)
+		if wait <= 0 {
+			l.reserveLocked(host, now)
+			l.mu.Unlock()
+			return nil
+		}
+		l.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// String describes the configured limits, e.g. "10/s, 2/s per host".
+func (l *Limiter) String() string {
+	var parts []string
+	if l.rate > 0 {
+		parts = append(parts, formatRate(l.rate))
+	}
+	if l.perHostRate > 0 {
+		parts = append(parts, formatRate(l.perHostRate)+" per host")
+	}
+	return strings.Join(parts, ", ")
+}
+
+func formatRate(rate float64) string {
+	return fmt.Sprintf("%g/s", rate)
+}
+
+// Sent returns the number of requests let through.
+func (l *Limiter) Sent() uint64 {
+	return l.sent.Load()
+}
+
+// EffectiveRate returns the requests per second let through over the last few
+// seconds. It is meant to be polled, e.g. by the progress tracker.
+func (l *Limiter) EffectiveRate() float64 {
+	l.statusMu.Lock()
+	defer l.statusMu.Unlock()
+
+	now := time.Now()
+	sent := l.sent.Load()
+	l.samples = append(l.samples, sample{at: now, sent: sent})
+	for len(l.samples) > 2 && now.Sub(l.samples[1].at) >= rateWindow {
+		l.samples = l.samples[1:]
+	}
+	oldest := l.samples[0]
+	elapsed := now.Sub(oldest.at).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(sent-oldest.sent) / elapsed
+}
@@ -0,0 +1,178 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Abhaythakor/hyperwapp/model"
+)
+
+type received struct {
+	host string
+	at   time.Duration
+}
+
+// run schedules urls and returns the host and arrival time of each target.
+func run(t *testing.T, l *Limiter, urls []string) []received {
+	t.Helper()
+	in := make(chan model.Target, len(urls))
+	for _, u := range urls {
+		in <- model.Target{URL: u}
+	}
+	close(in)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	start := time.Now()
+	var got []received
+	for target := range l.Schedule(ctx, in) {
+		got = append(got, received{Host(target.URL), time.Since(start)})
+	}
+	if len(got) != len(urls) {
+		t.Fatalf("Schedule() delivered %d targets, want %d", len(got), len(urls))
+	}
+	return got
+}
+
+func TestNew(t *testing.T) {
+	if l := New(0, 0); l != nil {
+		t.Errorf("New(0, 0) = %v, want nil", l)
+	}
+	if got := New(10, 0.5).String(); got != "10/s, 0.5/s per host" {
+		t.Errorf("String() = %q", got)
+	}
+	if got := New(0, 2).String(); got != "2/s per host" {
+		t.Errorf("String() = %q", got)
+	}
+
+	var l *Limiter
+	if err := l.Wait(context.Background(), "https://a.com"); err != nil {
+		t.Errorf("Wait() on a nil Limiter = %v", err)
+	}
+}
+
+func TestHost(t *testing.T) {
+	tests := map[string]string{
+		"https://A.com:8443/path": "a.com",
+		"http://10.0.0.1/":        "10.0.0.1",
+		"http://[::1]:8080":       "::1",
+		"not a url":               "not a url",
+	}
+	for in, want := range tests {
+		if got := Host(in); got != want {
+			t.Errorf("Host(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestScheduleGlobalRate(t *testing.T) {
+	var urls []string
+	for i := 0; i < 11; i++ {
+		urls = append(urls, fmt.Sprintf("https://host%d.com", i))
+	}
+	got := run(t, New(50, 0), urls)
+	// 11 requests at 50/s need 10 intervals of 20ms
+	if last := got[len(got)-1].at; last < 180*time.Millisecond {
+		t.Errorf("11 targets at 50/s delivered in %v, want at least 200ms", last)
+	}
+}
+
+func TestSchedulePerHostDoesNotStallOthers(t *testing.T) {
+	urls := []string{"https://slow.com/1", "https://slow.com/2", "https://slow.com/3"}
+	for i := 0; i < 10; i++ {
+		urls = append(urls, fmt.Sprintf("https://fast%d.com/", i))
+	}
+	got := run(t, New(0, 5), urls) // One request per 200ms per host
+
+	var slow []time.Duration
+	for _, r := range got {
+		if r.host == "slow.com" {
+			slow = append(slow, r.at)
+		} else if r.at > 150*time.Millisecond {
+			t.Errorf("%s delivered after %v, waiting behind slow.com", r.host, r.at)
+		}
+	}
+	for i := 1; i < len(slow); i++ {
+		if gap := slow[i] - slow[i-1]; gap < 180*time.Millisecond {
+			t.Errorf("slow.com requests %d and %d only %v apart, want 200ms", i-1, i, gap)
+		}
+	}
+}
+
+func TestScheduleWithConcurrentWait(t *testing.T) {
+	l := New(0, 20) // 50ms per host
+	in := make(chan model.Target, 5)
+	for i := 0; i < 5; i++ {
+		in <- model.Target{URL: fmt.Sprintf("https://a.com/%d", i)}
+	}
+	close(in)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Workers reserve the scheduled targets while retries hit the same host
+	var (
+		mu   sync.Mutex
+		sent []time.Time
+		wg   sync.WaitGroup
+	)
+	wait := func(rawURL string) {
+		if err := l.Wait(ctx, rawURL); err != nil {
+			t.Error(err)
+			return
+		}
+		mu.Lock()
+		sent = append(sent, time.Now())
+		mu.Unlock()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 5; i++ {
+			wait("https://a.com/retry")
+		}
+	}()
+	for target := range l.Schedule(ctx, in) {
+		wait(target.URL)
+	}
+	wg.Wait()
+
+	slices.SortFunc(sent, func(a, b time.Time) int { return a.Compare(b) })
+	for i := 1; i < len(sent); i++ {
+		if gap := sent[i].Sub(sent[i-1]); gap < 40*time.Millisecond {
+			t.Errorf("requests %d and %d only %v apart, want 50ms", i-1, i, gap)
+		}
+	}
+	if l.Sent() != 10 {
+		t.Errorf("Sent() = %d, want 10", l.Sent())
+	}
+}
+
+func TestWaitSharesHostBudget(t *testing.T) {
+	l := New(0, 10) // 100ms per host
+	ctx := context.Background()
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := l.Wait(ctx, "https://a.com/favicon.ico"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 180*time.Millisecond {
+		t.Errorf("3 requests to one host at 10/s took %v, want at least 200ms", elapsed)
+	}
+	if err := l.Wait(ctx, "https://b.com/"); err != nil || time.Since(start) > 300*time.Millisecond {
+		t.Errorf("another host should not wait (err %v)", err)
+	}
+	if l.Sent() != 4 {
+		t.Errorf("Sent() = %d, want 4", l.Sent())
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := l.Wait(cancelled, "https://a.com/"); err == nil {
+		t.Error("Wait() with a cancelled context should fail")
+	}
+}
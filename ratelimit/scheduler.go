@@ -0,0 +1,198 @@
+package ratelimit
+
+import (
+	"container/heap"
+	"context"
+	"time"
+
+	"github.com/Abhaythakor/hyperwapp/model"
+)
+
+// MaxPending is the number of targets the scheduler buffers while their hosts
+// are waiting for a slot.
+const MaxPending = 100000
+
+// Schedule forwards targets from in to the returned channel no faster than
+// the limiter allows. Targets of a host at its limit wait in a per-host queue
+// while targets of other hosts are handed out, so one busy host never stalls
+// the workers. The channel is closed once in is drained or ctx is done.
+//
+// The scheduler only paces and orders the targets: a worker must still call
+// Wait for a target before sending its request, which reserves the slot
+// atomically with any concurrent Wait for retries or follow-ups. Targets
+// handed out hold their host until its next slot, so that Wait rarely blocks.
+func (l *Limiter) Schedule(ctx context.Context, in <-chan model.Target) <-chan model.Target {
+	out := make(chan model.Target)
+	go func() {
+		defer close(out)
+
+		q := newHostQueue()
+		timer := time.NewTimer(time.Hour)
+		defer timer.Stop()
+		var held time.Time // Next global slot after the targets handed out
+
+		for {
+			if in == nil && q.pending == 0 {
+				return
+			}
+			inCh := in
+			if q.pending >= MaxPending {
+				inCh = nil // Stop reading until the buffered targets drain
+			}
+
+			var (
+				outCh     chan<- model.Target
+				candidate model.Target
+				host      string
+				timerC    <-chan time.Time
+			)
+			now := time.Now()
+			if h, ok := q.peek(l, now); ok {
+				if wait := max(l.delay(h.host, now), h.at.Sub(now), held.Sub(now)); wait > 0 {
+					timer.Reset(wait)
+					timerC = timer.C
+				} else {
+					host, candidate, outCh = h.host, q.head(h.host), out
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case target, ok := <-inCh:
+				if !ok {
+					in = nil
+					continue
+				}
+				q.push(Host(target.URL), target)
+			case outCh <- candidate:
+				// Hold the slots from when a worker takes the target, not
+				// from when it was picked, so the spacing holds for the
+				// requests actually sent.
+				now = time.Now()
+				held = now.Add(l.global)
+				var next time.Time
+				if l.perHost > 0 {
+					next = now.Add(l.perHost)
+				}
+				q.pop(host, next)
+			case <-timerC:
+			}
+		}
+	}()
+	return out
+}
+
+// hostQueue holds pending targets per host, with the hosts ordered by the
+// time of their next allowed request. A host stays queued without targets
+// until its held slot has passed, so a target arriving for it meanwhile
+// still waits.
+type hostQueue struct {
+	targets map[string][]model.Target
+	ready   readyHeap
+	index   map[string]*readyHost
+	seq     uint64
+	pending int
+}
+
+type readyHost struct {
+	host  string
+	at    time.Time // Next allowed request, held by the scheduler or reserved by Wait
+	seq   uint64    // FIFO order among hosts ready at the same time
+	index int
+}
+
+func newHostQueue() *hostQueue {
+	return &hostQueue{targets: make(map[string][]model.Target), index: make(map[string]*readyHost)}
+}
+
+func (q *hostQueue) push(host string, target model.Target) {
+	q.targets[host] = append(q.targets[host], target)
+	q.pending++
+	if _, ok := q.index[host]; !ok {
+		q.seq++
+		h := &readyHost{host: host, seq: q.seq}
+		q.index[host] = h
+		heap.Push(&q.ready, h)
+	}
+}
+
+// peek returns the host whose next request is allowed first, refreshing keys
+// made stale by requests reserved with Limiter.Wait and dropping hosts without
+// targets whose slot has passed.
+func (q *hostQueue) peek(l *Limiter, now time.Time) (*readyHost, bool) {
+	for len(q.ready) > 0 {
+		top := q.ready[0]
+		if len(q.targets[top.host]) == 0 && !top.at.After(now) {
+			delete(q.index, top.host)
+			heap.Pop(&q.ready)
+			continue
+		}
+		l.mu.Lock()
+		next := l.hosts[top.host]
+		l.mu.Unlock()
+		if !next.After(top.at) {
+			return top, true
+		}
+		top.at = next
+		heap.Fix(&q.ready, top.index)
+	}
+	return nil, false
+}
+
+func (q *hostQueue) head(host string) model.Target {
+	return q.targets[host][0]
+}
+
+// pop removes the first target of host after it was sent and requeues the
+// host for its next slot (zero when unlimited).
+func (q *hostQueue) pop(host string, next time.Time) {
+	targets := q.targets[host]
+	targets[0] = model.Target{}
+	targets = targets[1:]
+	q.pending--
+
+	h := q.index[host]
+	if len(targets) == 0 {
+		delete(q.targets, host)
+		if next.IsZero() {
+			delete(q.index, host)
+			heap.Remove(&q.ready, h.index)
+			return
+		}
+	} else {
+		q.targets[host] = targets
+	}
+	q.seq++
+	h.at = next
+	h.seq = q.seq // Round-robin among ready hosts
+	heap.Fix(&q.ready, h.index)
+}
+
+// readyHeap is a min-heap of hosts by next allowed request time.
+type readyHeap []*readyHost
+
+func (h readyHeap) Len() int { return len(h) }
+func (h readyHeap) Less(i, j int) bool {
+	if !h[i].at.Equal(h[j].at) {
+		return h[i].at.Before(h[j].at)
+	}
+	return h[i].seq < h[j].seq
+}
+func (h readyHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+func (h *readyHeap) Push(x any) {
+	item := x.(*readyHost)
+	item.index = len(*h)
+	*h = append(*h, item)
+}
+func (h *readyHeap) Pop() any {
+	old := *h
+	item := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return item
+}
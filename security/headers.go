@@ -0,0 +1,331 @@
+// Package security grades the security headers of HTTP responses.
+package security
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Abhaythakor/hyperwapp/model"
+)
+
+// Finding IDs.
+const (
+	MissingCSP                 = "missing-csp"
+	CSPReportOnly              = "csp-report-only"
+	CSPNoScriptSrc             = "csp-no-script-src"
+	CSPUnsafeInline            = "csp-unsafe-inline"
+	CSPUnsafeEval              = "csp-unsafe-eval"
+	CSPWildcardSource          = "csp-wildcard-source"
+	MissingHSTS                = "missing-hsts"
+	InvalidHSTS                = "invalid-hsts"
+	HSTSShortMaxAge            = "hsts-short-max-age"
+	MissingXFrameOptions       = "missing-x-frame-options"
+	InvalidXFrameOptions       = "invalid-x-frame-options"
+	CookieWithoutSecure        = "cookie-without-secure"
+	CookieWithoutHTTPOnly      = "cookie-without-httponly"
+	CookieWithoutSameSite      = "cookie-without-samesite"
+	CookieSameSiteNoneInsecure = "cookie-samesite-none-without-secure"
+	ServerVersionDisclosure    = "server-version-disclosure"
+	PoweredByDisclosure        = "x-powered-by-disclosure"
+)
+
+// MinHSTSMaxAge is the shortest HSTS max-age (180 days) not reported as too short.
+const MinHSTSMaxAge = 180 * 24 * 60 * 60
+
+// penalties are deducted from the score of 100 once per finding ID, so a
+// response setting ten insecure cookies is not graded below one missing CSP.
+var penalties = map[string]int{
+	model.SeverityMedium: 20,
+	model.SeverityLow:    5,
+}
+
+var versionPattern = regexp.MustCompile(`\d+\.\d+`)
+
+// Analyze grades the headers of the response to rawURL. HSTS and the cookie
+// Secure flag are only checked when the URL is known to use https. It returns
+// nil for responses without headers (e.g. body-only offline inputs).
+func Analyze(domain, rawURL string, headers map[string][]string) *model.SecurityReport {
+	if len(headers) == 0 {
+		return nil
+	}
+	https := false
+	if u, err := url.Parse(rawURL); err == nil {
+		https = strings.EqualFold(u.Scheme, "https")
+	}
+
+	var findings []model.SecurityFinding
+	add := func(id, severity, header, message, value string) {
+		findings = append(findings, model.SecurityFinding{ID: id, Severity: severity, Header: header, Message: message, Value: value})
+	}
+
+	// Content-Security-Policy. Browsers enforce every policy sent, so a script
+	// runs only if all of them allow it: a weakness counts when none forbids it.
+	policies := parseCSPs(values(headers, "Content-Security-Policy"))
+	switch {
+	case len(policies) == 0 && len(values(headers, "Content-Security-Policy-Report-Only")) > 0:
+		add(CSPReportOnly, model.SeverityLow, "Content-Security-Policy-Report-Only", "Content-Security-Policy is only set in report-only mode", "")
+	case len(policies) == 0:
+		add(MissingCSP, model.SeverityMedium, "Content-Security-Policy", "Content-Security-Policy header is missing", "")
+	default:
+		// Script sources of the policies restricting scripts; the others allow everything
+		var scriptSources [][]string
+		for _, csp := range policies {
+			if scripts, ok := csp["script-src"]; ok {
+				scriptSources = append(scriptSources, scripts)
+			} else if scripts, ok := csp["default-src"]; ok {
+				scriptSources = append(scriptSources, scripts)
+			}
+		}
+		everyPolicy := func(allows func(scripts []string) bool) bool {
+			for _, scripts := range scriptSources {
+				if !allows(scripts) {
+					return false
+				}
+			}
+			return true
+		}
+
+		if len(scriptSources) == 0 {
+			add(CSPNoScriptSrc, model.SeverityLow, "Content-Security-Policy", "CSP does not restrict scripts (no script-src or default-src)", "")
+			break
+		}
+		if everyPolicy(func(scripts []string) bool { return contains(scripts, "'unsafe-inline'") && !hasNonceOrHash(scripts) }) {
+			add(CSPUnsafeInline, model.SeverityLow, "Content-Security-Policy", "CSP allows inline scripts ('unsafe-inline')", "")
+		}
+		if everyPolicy(func(scripts []string) bool { return contains(scripts, "'unsafe-eval'") }) {
+			add(CSPUnsafeEval, model.SeverityLow, "Content-Security-Policy", "CSP allows eval() ('unsafe-eval')", "")
+		}
+		if everyPolicy(func(scripts []string) bool { return wildcardSource(scripts) != "" }) {
+			add(CSPWildcardSource, model.SeverityMedium, "Content-Security-Policy", "CSP allows scripts from any host", wildcardSource(scriptSources[0]))
+		}
+	}
+
+	// Strict-Transport-Security
+	if https {
+		if hsts := first(headers, "Strict-Transport-Security"); hsts == "" {
+			add(MissingHSTS, model.SeverityMedium, "Strict-Transport-Security", "Strict-Transport-Security header is missing", "")
+		} else if maxAge, ok := hstsMaxAge(hsts); !ok {
+			add(InvalidHSTS, model.SeverityLow, "Strict-Transport-Security", "Strict-Transport-Security has no valid max-age", hsts)
+		} else if maxAge < MinHSTSMaxAge {
+			add(HSTSShortMaxAge, model.SeverityLow, "Strict-Transport-Security", "Strict-Transport-Security max-age is shorter than 180 days", hsts)
+		}
+	}
+
+	// X-Frame-Options (superseded by CSP frame-ancestors)
+	if xfo := first(headers, "X-Frame-Options"); xfo == "" {
+		if !hasDirective(policies, "frame-ancestors") {
+			add(MissingXFrameOptions, model.SeverityMedium, "X-Frame-Options", "Neither X-Frame-Options nor CSP frame-ancestors prevents framing", "")
+		}
+	} else if v := strings.ToUpper(strings.TrimSpace(xfo)); v != "DENY" && v != "SAMEORIGIN" {
+		add(InvalidXFrameOptions, model.SeverityLow, "X-Frame-Options", "X-Frame-Options should be DENY or SAMEORIGIN", xfo)
+	}
+
+	// Set-Cookie flags
+	for _, cookie := range values(headers, "Set-Cookie") {
+		name, attrs := parseCookie(cookie)
+		if name == "" {
+			continue
+		}
+		_, secure := attrs["secure"]
+		if _, ok := attrs["httponly"]; !ok {
+			add(CookieWithoutHTTPOnly, model.SeverityLow, "Set-Cookie", "Cookie is readable by scripts (no HttpOnly)", name)
+		}
+		sameSite, ok := attrs["samesite"]
+		switch {
+		case !ok:
+			add(CookieWithoutSameSite, model.SeverityLow, "Set-Cookie", "Cookie has no SameSite attribute", name)
+		case strings.EqualFold(sameSite, "none") && !secure:
+			add(CookieSameSiteNoneInsecure, model.SeverityMedium, "Set-Cookie", "Cookie uses SameSite=None without Secure", name)
+			continue
+		}
+		if https && !secure {
+			add(CookieWithoutSecure, model.SeverityMedium, "Set-Cookie", "Cookie is sent over plain HTTP (no Secure)", name)
+		}
+	}
+
+	// Version disclosure
+	if server := first(headers, "Server"); versionPattern.MatchString(server) {
+		add(ServerVersionDisclosure, model.SeverityLow, "Server", "Server header discloses the software version", server)
+	}
+	if poweredBy := first(headers, "X-Powered-By"); poweredBy != "" {
+		add(PoweredByDisclosure, model.SeverityLow, "X-Powered-By", "X-Powered-By header discloses the application stack", poweredBy)
+	}
+
+	score := Score(findings)
+	return &model.SecurityReport{
+		Type:      model.RecordSecurityHeaders,
+		Domain:    domain,
+		URL:       rawURL,
+		Grade:     Grade(score),
+		Score:     score,
+		Findings:  findings,
+		Timestamp: time.Now().UTC(),
+	}
+}
+
+// Score deducts the penalty of each distinct finding ID from 100.
+func Score(findings []model.SecurityFinding) int {
+	score := 100
+	seen := make(map[string]struct{}, len(findings))
+	for _, f := range findings {
+		if _, ok := seen[f.ID]; ok {
+			continue
+		}
+		seen[f.ID] = struct{}{}
+		score -= penalties[f.Severity]
+	}
+	if score < 0 {
+		score = 0
+	}
+	return score
+}
+
+// Grade maps a score to a letter grade.
+func Grade(score int) string {
+	switch {
+	case score >= 90:
+		return "A"
+	case score >= 75:
+		return "B"
+	case score >= 60:
+		return "C"
+	case score >= 40:
+		return "D"
+	default:
+		return "F"
+	}
+}
+
+// values returns the values of a header, matching its name case-insensitively
+// since offline parsers do not all canonicalize header names.
+func values(headers map[string][]string, name string) []string {
+	if v, ok := headers[name]; ok {
+		return v
+	}
+	for key, v := range headers {
+		if strings.EqualFold(key, name) {
+			return v
+		}
+	}
+	return nil
+}
+
+func first(headers map[string][]string, name string) string {
+	for _, v := range values(headers, name) {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// parseCSPs parses the Content-Security-Policy header values. Each value, and
+// each comma-separated policy within a value, is a policy of its own.
+func parseCSPs(headerValues []string) []map[string][]string {
+	var policies []map[string][]string
+	for _, value := range headerValues {
+		for _, policy := range strings.Split(value, ",") {
+			if csp := parseCSP(policy); csp != nil {
+				policies = append(policies, csp)
+			}
+		}
+	}
+	return policies
+}
+
+// hasDirective reports whether any of the policies sets directive.
+func hasDirective(policies []map[string][]string, directive string) bool {
+	for _, csp := range policies {
+		if _, ok := csp[directive]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// wildcardSource returns the source of a list that allows any host, or "".
+func wildcardSource(sources []string) string {
+	for _, source := range sources {
+		if source == "*" || source == "http:" || source == "https:" {
+			return source
+		}
+	}
+	return ""
+}
+
+// parseCSP splits a policy into lowercased directives and their sources. The
+// first occurrence of a directive wins, as in browsers. It returns nil for an
+// empty policy.
+func parseCSP(policy string) map[string][]string {
+	directives := make(map[string][]string)
+	for _, directive := range strings.Split(policy, ";") {
+		fields := strings.Fields(directive)
+		if len(fields) == 0 {
+			continue
+		}
+		name := strings.ToLower(fields[0])
+		if _, ok := directives[name]; ok {
+			continue
+		}
+		sources := fields[1:]
+		for i := range sources {
+			sources[i] = strings.ToLower(sources[i])
+		}
+		directives[name] = sources
+	}
+	if len(directives) == 0 {
+		return nil
+	}
+	return directives
+}
+
+func contains(sources []string, source string) bool {
+	for _, s := range sources {
+		if s == source {
+			return true
+		}
+	}
+	return false
+}
+
+// hasNonceOrHash reports whether a source list uses nonces or hashes, which
+// make browsers ignore 'unsafe-inline'.
+func hasNonceOrHash(sources []string) bool {
+	for _, s := range sources {
+		if strings.HasPrefix(s, "'nonce-") || strings.HasPrefix(s, "'sha256-") || strings.HasPrefix(s, "'sha384-") || strings.HasPrefix(s, "'sha512-") {
+			return true
+		}
+	}
+	return false
+}
+
+func hstsMaxAge(value string) (int, bool) {
+	for _, directive := range strings.Split(value, ";") {
+		name, v, ok := strings.Cut(strings.TrimSpace(directive), "=")
+		if !ok || !strings.EqualFold(strings.TrimSpace(name), "max-age") {
+			continue
+		}
+		maxAge, err := strconv.Atoi(strings.Trim(strings.TrimSpace(v), `"`))
+		if err != nil || maxAge < 0 {
+			return 0, false
+		}
+		return maxAge, true
+	}
+	return 0, false
+}
+
+// parseCookie returns the name of a Set-Cookie value and its lowercased
+// attributes.
+func parseCookie(cookie string) (string, map[string]string) {
+	parts := strings.Split(cookie, ";")
+	name, _, _ := strings.Cut(parts[0], "=")
+	attrs := make(map[string]string, len(parts)-1)
+	for _, part := range parts[1:] {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		attrs[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(value)
+	}
+	return strings.TrimSpace(name), attrs
+}
@@ -0,0 +1,149 @@
+package security
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/Abhaythakor/hyperwapp/model"
+	"github.com/Abhaythakor/hyperwapp/util"
+)
+
+func findingIDs(report *model.SecurityReport) []string {
+	var ids []string
+	for _, f := range report.Findings {
+		ids = append(ids, f.ID)
+	}
+	return ids
+}
+
+func TestAnalyze(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		headers map[string][]string
+		want    []string
+		grade   string
+	}{
+		{
+			name: "Hardened",
+			url:  "https://a.com",
+			headers: map[string][]string{
+				"Content-Security-Policy":   {"default-src 'self'; script-src 'self' 'nonce-abc' 'unsafe-inline'; frame-ancestors 'none'"},
+				"Strict-Transport-Security": {"max-age=31536000; includeSubDomains"},
+				"Set-Cookie":                {"session=1; Path=/; Secure; HttpOnly; SameSite=Lax"},
+				"Server":                    {"nginx"},
+			},
+			want:  nil,
+			grade: "A",
+		},
+		{
+			name: "Bare",
+			url:  "https://a.com",
+			headers: map[string][]string{
+				"Server":       {"Apache/2.4.41 (Ubuntu)"},
+				"X-Powered-By": {"PHP/7.4.3"},
+			},
+			want:  []string{MissingCSP, MissingHSTS, MissingXFrameOptions, ServerVersionDisclosure, PoweredByDisclosure},
+			grade: "F",
+		},
+		{
+			name: "Weak policies",
+			url:  "https://a.com",
+			headers: map[string][]string{
+				"content-security-policy":   {"img-src *; script-src 'self' 'unsafe-inline' 'unsafe-eval' https:"},
+				"strict-transport-security": {"max-age=3600"},
+				"x-frame-options":           {"ALLOW-FROM https://b.com"},
+			},
+			want:  []string{CSPUnsafeInline, CSPUnsafeEval, CSPWildcardSource, HSTSShortMaxAge, InvalidXFrameOptions},
+			grade: "C",
+		},
+		{
+			name: "Every policy must allow a weakness",
+			url:  "https://a.com",
+			headers: map[string][]string{
+				"Content-Security-Policy": {
+					"script-src * 'unsafe-inline' 'unsafe-eval'",
+					"script-src 'self' 'unsafe-eval'; frame-ancestors 'self'",
+				},
+				"Strict-Transport-Security": {"max-age=31536000"},
+			},
+			want:  []string{CSPUnsafeEval},
+			grade: "A",
+		},
+		{
+			name: "Plain HTTP skips HSTS and Secure",
+			url:  "http://a.com",
+			headers: map[string][]string{
+				"Content-Security-Policy-Report-Only": {"default-src 'self'"},
+				"X-Frame-Options":                     {"sameorigin"},
+				"Set-Cookie":                          {"id=1; HttpOnly", "track=2; SameSite=None; HttpOnly"},
+			},
+			want:  []string{CSPReportOnly, CookieWithoutSameSite, CookieSameSiteNoneInsecure},
+			grade: "C",
+		},
+		{
+			name: "Cookies over HTTPS",
+			url:  "https://a.com",
+			headers: map[string][]string{
+				"Content-Security-Policy":   {"object-src 'none'"},
+				"Strict-Transport-Security": {"includeSubDomains"},
+				"X-Frame-Options":           {"DENY"},
+				"Set-Cookie":                {"a=1; SameSite=Lax", "b=2; SameSite=Strict"},
+			},
+			want:  []string{CSPNoScriptSrc, InvalidHSTS, CookieWithoutHTTPOnly, CookieWithoutSecure, CookieWithoutHTTPOnly, CookieWithoutSecure},
+			grade: "C",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := Analyze("a.com", tt.url, tt.headers)
+			if report == nil {
+				t.Fatal("Analyze() = nil")
+			}
+			if got := strings.Join(findingIDs(report), ","); got != strings.Join(tt.want, ",") {
+				t.Errorf("findings = %s, want %s", got, strings.Join(tt.want, ","))
+			}
+			if report.Grade != tt.grade {
+				t.Errorf("grade = %s (%d), want %s", report.Grade, report.Score, tt.grade)
+			}
+			if report.Type != model.RecordSecurityHeaders || report.Domain != "a.com" || report.URL != tt.url {
+				t.Errorf("report = %+v", report)
+			}
+		})
+	}
+
+	if report := Analyze("a.com", "https://a.com", nil); report != nil {
+		t.Errorf("Analyze() without headers = %+v, want nil", report)
+	}
+}
+
+func TestScoreCountsEachFindingOnce(t *testing.T) {
+	findings := []model.SecurityFinding{
+		{ID: CookieWithoutSecure, Severity: model.SeverityMedium},
+		{ID: CookieWithoutSecure, Severity: model.SeverityMedium},
+		{ID: ServerVersionDisclosure, Severity: model.SeverityLow},
+	}
+	if got := Score(findings); got != 75 {
+		t.Errorf("Score() = %d, want 75", got)
+	}
+}
+
+func TestSummary(t *testing.T) {
+	summary := NewSummary()
+	summary.Record(Analyze("a.com", "https://a.com", map[string][]string{"Server": {"nginx/1.25.3"}}))
+	summary.Record(Analyze("b.com", "http://b.com", map[string][]string{"X-Frame-Options": {"DENY"}, "Content-Security-Policy": {"default-src 'self'"}}))
+	summary.Record(nil)
+	if summary.Targets() != 2 {
+		t.Errorf("Targets() = %d, want 2", summary.Targets())
+	}
+
+	var out bytes.Buffer
+	summary.WriteSummary(&out, util.NewColorizer(false))
+	for _, want := range []string{"2 targets graded (A 1, F 1)", "1 (50%)", MissingCSP, ServerVersionDisclosure} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("WriteSummary() missing %q:\n%s", want, out.String())
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package security
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Abhaythakor/hyperwapp/model"
+	"github.com/Abhaythakor/hyperwapp/util"
+)
+
+// Summary counts grades and findings over a scan for the end-of-scan table.
+// It is not safe for concurrent use.
+type Summary struct {
+	targets  int
+	grades   map[string]int
+	findings map[string]int // Finding ID -> targets affected
+	severity map[string]string
+}
+
+// NewSummary creates an empty Summary.
+func NewSummary() *Summary {
+	return &Summary{grades: make(map[string]int), findings: make(map[string]int), severity: make(map[string]string)}
+}
+
+// Record adds a report to the summary. A nil report is ignored.
+func (s *Summary) Record(report *model.SecurityReport) {
+	if report == nil {
+		return
+	}
+	s.targets++
+	s.grades[report.Grade]++
+	seen := make(map[string]struct{}, len(report.Findings))
+	for _, f := range report.Findings {
+		if _, ok := seen[f.ID]; ok {
+			continue
+		}
+		seen[f.ID] = struct{}{}
+		s.findings[f.ID]++
+		s.severity[f.ID] = f.Severity
+	}
+}
+
+// Targets returns the number of graded targets.
+func (s *Summary) Targets() int {
+	return s.targets
+}
+
+// WriteSummary prints the grade distribution and how many targets each
+// finding affects. Nothing is written when no target was graded.
+func (s *Summary) WriteSummary(w io.Writer, color *util.Colorizer) {
+	if s.targets == 0 {
+		return
+	}
+
+	var grades []string
+	for _, grade := range []string{"A", "B", "C", "D", "F"} {
+		if n := s.grades[grade]; n > 0 {
+			grades = append(grades, fmt.Sprintf("%s %d", grade, n))
+		}
+	}
+	fmt.Fprintf(w, "\n[+] %s: %d targets graded (%s)\n", color.Cyan("Security Headers"), s.targets, strings.Join(grades, ", "))
+	if len(s.findings) == 0 {
+		fmt.Fprintln(w)
+		return
+	}
+
+	ids := make([]string, 0, len(s.findings))
+	for id := range s.findings {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if s.findings[ids[i]] != s.findings[ids[j]] {
+			return s.findings[ids[i]] > s.findings[ids[j]]
+		}
+		return ids[i] < ids[j]
+	})
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "    TARGETS\tSEVERITY\tFINDING")
+	for _, id := range ids {
+		fmt.Fprintf(tw, "    %d (%.0f%%)\t%s\t%s\n", s.findings[id], 100*float64(s.findings[id])/float64(s.targets), s.severity[id], id)
+	}
+	tw.Flush()
+	fmt.Fprintln(w)
+}
@@ -0,0 +1,49 @@
+package util
+
+import (
+	"bufio"
+	"os"
+	"sync"
+)
+
+// LineWriter writes lines to a file from concurrent goroutines. A nil
+// *LineWriter discards everything, so callers need not check whether the
+// file was requested.
+type LineWriter struct {
+	mu   sync.Mutex
+	file *os.File
+	w    *bufio.Writer
+}
+
+// NewLineWriter creates (or truncates) the file at path.
+func NewLineWriter(path string) (*LineWriter, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &LineWriter{file: file, w: bufio.NewWriter(file)}, nil
+}
+
+// WriteLine appends line and a newline.
+func (lw *LineWriter) WriteLine(line string) {
+	if lw == nil {
+		return
+	}
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	_, _ = lw.w.WriteString(line + "\n")
+}
+
+// Close flushes and closes the file.
+func (lw *LineWriter) Close() error {
+	if lw == nil {
+		return nil
+	}
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	if err := lw.w.Flush(); err != nil {
+		lw.file.Close()
+		return err
+	}
+	return lw.file.Close()
+}
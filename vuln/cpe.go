@@ -0,0 +1,106 @@
+package vuln
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// splitCPE splits a CPE 2.3 formatted string into its fields, honouring
+// backslash-escaped colons (cpe:2.3:a:vendor:product:version:...).
+func splitCPE(cpe string) []string {
+	var fields []string
+	var current strings.Builder
+	escaped := false
+	for _, r := range cpe {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == ':':
+			fields = append(fields, current.String())
+			current.Reset()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	return append(fields, current.String())
+}
+
+// parseCPE returns the "vendor:product" key and the version field of a CPE.
+func parseCPE(cpe string) (key, version string, ok bool) {
+	fields := splitCPE(strings.ToLower(strings.TrimSpace(cpe)))
+	if len(fields) < 5 || fields[0] != "cpe" || fields[1] != "2.3" {
+		return "", "", false
+	}
+	if len(fields) > 5 {
+		version = fields[5]
+	}
+	return fields[3] + ":" + fields[4], version, true
+}
+
+// anyVersion reports whether a CPE version field matches every version.
+func anyVersion(v string) bool {
+	return v == "" || v == "*" || v == "-"
+}
+
+// compareVersions compares dotted versions segment by segment: numeric
+// segments numerically, others lexically. Missing segments count as 0, so
+// "1.2" equals "1.2.0".
+func compareVersions(a, b string) int {
+	as, bs := versionSegments(a), versionSegments(b)
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		x, y := "0", "0"
+		if i < len(as) {
+			x = as[i]
+		}
+		if i < len(bs) {
+			y = bs[i]
+		}
+		if c := compareSegment(x, y); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func versionSegments(v string) []string {
+	return strings.FieldsFunc(strings.ToLower(v), func(r rune) bool {
+		return r == '.' || r == '-' || r == '_' || r == '+'
+	})
+}
+
+func compareSegment(x, y string) int {
+	xn, xerr := strconv.Atoi(x)
+	yn, yerr := strconv.Atoi(y)
+	switch {
+	case xerr == nil && yerr == nil:
+		return compareInts(xn, yn)
+	case xerr == nil:
+		// "1.0" sorts after "1.0-rc1": a number beats a pre-release tag
+		if startsWithLetter(y) {
+			return 1
+		}
+	case yerr == nil:
+		if startsWithLetter(x) {
+			return -1
+		}
+	}
+	return strings.Compare(x, y)
+}
+
+func compareInts(x, y int) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
+
+func startsWithLetter(s string) bool {
+	return s != "" && unicode.IsLetter(rune(s[0]))
+}
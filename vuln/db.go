@@ -0,0 +1,399 @@
+package vuln
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Abhaythakor/hyperwapp/model"
+	"github.com/Abhaythakor/hyperwapp/util"
+)
+
+// entry is one vulnerable CPE match of a CVE.
+type entry struct {
+	cve       *cve
+	version   string // Exact version from the CPE, empty for any
+	startIncl string
+	startExcl string
+	endIncl   string
+	endExcl   string
+}
+
+// cve is shared by all the CPE matches of one CVE.
+type cve struct {
+	id       string
+	cvss     float64
+	severity string
+}
+
+// DB indexes NVD CVE feeds by CPE vendor and product.
+type DB struct {
+	products map[string][]entry // vendor:product -> matches
+	cves     int
+}
+
+// Load reads every NVD JSON feed in dir (*.json or *.json.gz, legacy 1.1
+// "CVE_Items" or API 2.0 "vulnerabilities" layout). Feeds are decoded one CVE
+// at a time. When cpes is not nil, only matches for the vendor and product of
+// those CPEs are kept, which keeps the index small.
+func Load(dir string, cpes []string) (*DB, error) {
+	var products map[string]struct{}
+	if cpes != nil {
+		products = make(map[string]struct{}, len(cpes))
+		for _, cpe := range cpes {
+			if key, _, ok := parseCPE(cpe); ok {
+				products[key] = struct{}{}
+			}
+		}
+	}
+
+	var files []string
+	for _, pattern := range []string{"*.json", "*.json.gz"} {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no NVD feeds (*.json, *.json.gz) found in %s", dir)
+	}
+	sort.Strings(files)
+
+	db := &DB{products: make(map[string][]entry)}
+	for _, path := range files {
+		if err := db.loadFile(path, products); err != nil {
+			return nil, fmt.Errorf("failed to load %s: %w", path, err)
+		}
+	}
+	return db, nil
+}
+
+// Products returns the number of indexed vendor:product keys.
+func (db *DB) Products() int {
+	return len(db.products)
+}
+
+// CVEs returns the number of CVEs with at least one indexed match.
+func (db *DB) CVEs() int {
+	return db.cves
+}
+
+func (db *DB) loadFile(path string, products map[string]struct{}) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var r io.Reader = bufio.NewReaderSize(file, 1024*1024)
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	dec := json.NewDecoder(r)
+	if tok, err := dec.Token(); err != nil {
+		return err
+	} else if tok != json.Delim('{') {
+		return fmt.Errorf("not an NVD JSON feed")
+	}
+
+	count := 0
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if key != "CVE_Items" && key != "vulnerabilities" {
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if tok, err := dec.Token(); err != nil {
+			return err
+		} else if tok != json.Delim('[') {
+			return fmt.Errorf("%s must be an array", key)
+		}
+		for dec.More() {
+			var added bool
+			if key == "CVE_Items" {
+				var item feedItem11
+				if err := dec.Decode(&item); err != nil {
+					return err
+				}
+				added = db.addItem11(item, products)
+			} else {
+				var item feedItem20
+				if err := dec.Decode(&item); err != nil {
+					return err
+				}
+				added = db.addItem20(item, products)
+			}
+			if added {
+				count++
+			}
+		}
+		if _, err := dec.Token(); err != nil { // Closing ]
+			return err
+		}
+	}
+
+	db.cves += count
+	util.Debug("Loaded %d CVEs from %s", count, path)
+	return nil
+}
+
+// add indexes a vulnerable CPE match. It reports whether the match was kept.
+func (db *DB) add(c *cve, cpe string, vulnerable bool, startIncl, startExcl, endIncl, endExcl string, products map[string]struct{}) bool {
+	if !vulnerable {
+		return false
+	}
+	key, version, ok := parseCPE(cpe)
+	if !ok {
+		return false
+	}
+	if products != nil {
+		if _, wanted := products[key]; !wanted {
+			return false
+		}
+	}
+	if anyVersion(version) {
+		version = ""
+	}
+	db.products[key] = append(db.products[key], entry{
+		cve:       c,
+		version:   version,
+		startIncl: startIncl,
+		startExcl: startExcl,
+		endIncl:   endIncl,
+		endExcl:   endExcl,
+	})
+	return true
+}
+
+// Lookup returns the CVEs affecting a CPE at the given version, highest CVSS
+// first. Detections without a version are never matched.
+func (db *DB) Lookup(cpe, version string) []model.Vulnerability {
+	if db == nil || version == "" {
+		return nil
+	}
+	key, _, ok := parseCPE(cpe)
+	if !ok {
+		return nil
+	}
+
+	var vulns []model.Vulnerability
+	seen := make(map[string]bool)
+	for _, e := range db.products[key] {
+		if seen[e.cve.id] || !e.matches(version) {
+			continue
+		}
+		seen[e.cve.id] = true
+		vulns = append(vulns, model.Vulnerability{
+			ID:       e.cve.id,
+			CVSS:     e.cve.cvss,
+			Severity: e.cve.severity,
+			Versions: e.rangeLabel(),
+		})
+	}
+	sort.SliceStable(vulns, func(i, j int) bool {
+		if vulns[i].CVSS != vulns[j].CVSS {
+			return vulns[i].CVSS > vulns[j].CVSS
+		}
+		return vulns[i].ID > vulns[j].ID // Newer CVEs first
+	})
+	return vulns
+}
+
+func (e entry) matches(version string) bool {
+	if e.version != "" {
+		return compareVersions(version, e.version) == 0
+	}
+	if e.startIncl == "" && e.startExcl == "" && e.endIncl == "" && e.endExcl == "" {
+		return false // Every version affected: too vague to report
+	}
+	if e.startIncl != "" && compareVersions(version, e.startIncl) < 0 {
+		return false
+	}
+	if e.startExcl != "" && compareVersions(version, e.startExcl) <= 0 {
+		return false
+	}
+	if e.endIncl != "" && compareVersions(version, e.endIncl) > 0 {
+		return false
+	}
+	if e.endExcl != "" && compareVersions(version, e.endExcl) >= 0 {
+		return false
+	}
+	return true
+}
+
+func (e entry) rangeLabel() string {
+	if e.version != "" {
+		return "= " + e.version
+	}
+	var parts []string
+	if e.startIncl != "" {
+		parts = append(parts, ">= "+e.startIncl)
+	}
+	if e.startExcl != "" {
+		parts = append(parts, "> "+e.startExcl)
+	}
+	if e.endIncl != "" {
+		parts = append(parts, "<= "+e.endIncl)
+	}
+	if e.endExcl != "" {
+		parts = append(parts, "< "+e.endExcl)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Legacy 1.1 feeds (nvdcve-1.1-2023.json).
+type feedItem11 struct {
+	CVE struct {
+		Meta struct {
+			ID string `json:"ID"`
+		} `json:"CVE_data_meta"`
+	} `json:"cve"`
+	Configurations struct {
+		Nodes []node11 `json:"nodes"`
+	} `json:"configurations"`
+	Impact struct {
+		V3 struct {
+			CVSS struct {
+				BaseScore    float64 `json:"baseScore"`
+				BaseSeverity string  `json:"baseSeverity"`
+			} `json:"cvssV3"`
+		} `json:"baseMetricV3"`
+		V2 struct {
+			CVSS struct {
+				BaseScore float64 `json:"baseScore"`
+			} `json:"cvssV2"`
+			Severity string `json:"severity"`
+		} `json:"baseMetricV2"`
+	} `json:"impact"`
+}
+
+type node11 struct {
+	Children []node11 `json:"children"`
+	Matches  []struct {
+		Vulnerable bool   `json:"vulnerable"`
+		CPE        string `json:"cpe23Uri"`
+		cpeRange
+	} `json:"cpe_match"`
+}
+
+type cpeRange struct {
+	StartIncl string `json:"versionStartIncluding"`
+	StartExcl string `json:"versionStartExcluding"`
+	EndIncl   string `json:"versionEndIncluding"`
+	EndExcl   string `json:"versionEndExcluding"`
+}
+
+func (db *DB) addItem11(item feedItem11, products map[string]struct{}) bool {
+	c := &cve{id: item.CVE.Meta.ID}
+	if v3 := item.Impact.V3.CVSS; v3.BaseScore > 0 {
+		c.cvss, c.severity = v3.BaseScore, v3.BaseSeverity
+	} else {
+		c.cvss, c.severity = item.Impact.V2.CVSS.BaseScore, item.Impact.V2.Severity
+	}
+
+	added := false
+	var walk func(nodes []node11)
+	walk = func(nodes []node11) {
+		for _, n := range nodes {
+			for _, m := range n.Matches {
+				if db.add(c, m.CPE, m.Vulnerable, m.StartIncl, m.StartExcl, m.EndIncl, m.EndExcl, products) {
+					added = true
+				}
+			}
+			walk(n.Children)
+		}
+	}
+	walk(item.Configurations.Nodes)
+	return added
+}
+
+// API 2.0 feeds (nvdcve-2.0-2024.json).
+type feedItem20 struct {
+	CVE struct {
+		ID      string `json:"id"`
+		Metrics struct {
+			V31 []cvssMetric20 `json:"cvssMetricV31"`
+			V30 []cvssMetric20 `json:"cvssMetricV30"`
+			V2  []cvssMetric20 `json:"cvssMetricV2"`
+		} `json:"metrics"`
+		Configurations []struct {
+			Nodes []struct {
+				Matches []struct {
+					Vulnerable bool   `json:"vulnerable"`
+					CPE        string `json:"criteria"`
+					cpeRange
+				} `json:"cpeMatch"`
+			} `json:"nodes"`
+		} `json:"configurations"`
+	} `json:"cve"`
+}
+
+type cvssMetric20 struct {
+	Type string `json:"type"` // Primary | Secondary
+	Data struct {
+		BaseScore    float64 `json:"baseScore"`
+		BaseSeverity string  `json:"baseSeverity"` // v3.x only
+	} `json:"cvssData"`
+	BaseSeverity string `json:"baseSeverity"` // v2 only
+}
+
+// score picks the primary (NVD) metric, or the first one.
+func score(metrics []cvssMetric20) (float64, string, bool) {
+	if len(metrics) == 0 {
+		return 0, "", false
+	}
+	m := metrics[0]
+	for _, candidate := range metrics {
+		if candidate.Type == "Primary" {
+			m = candidate
+			break
+		}
+	}
+	severity := m.Data.BaseSeverity
+	if severity == "" {
+		severity = m.BaseSeverity
+	}
+	return m.Data.BaseScore, severity, true
+}
+
+func (db *DB) addItem20(item feedItem20, products map[string]struct{}) bool {
+	c := &cve{id: item.CVE.ID}
+	for _, metrics := range [][]cvssMetric20{item.CVE.Metrics.V31, item.CVE.Metrics.V30, item.CVE.Metrics.V2} {
+		if cvss, severity, ok := score(metrics); ok {
+			c.cvss, c.severity = cvss, severity
+			break
+		}
+	}
+
+	added := false
+	for _, config := range item.CVE.Configurations {
+		for _, n := range config.Nodes {
+			for _, m := range n.Matches {
+				if db.add(c, m.CPE, m.Vulnerable, m.StartIncl, m.StartExcl, m.EndIncl, m.EndExcl, products) {
+					added = true
+				}
+			}
+		}
+	}
+	return added
+}
@@ -0,0 +1,193 @@
+package vuln
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Abhaythakor/hyperwapp/model"
+	"github.com/Abhaythakor/hyperwapp/util"
+)
+
+// Legacy 1.1 feed: one range match nested in a child node, one exact version.
+const feed11 = `{
+  "CVE_data_type": "CVE",
+  "CVE_Items": [
+    {
+      "cve": {"CVE_data_meta": {"ID": "CVE-2023-0001"}},
+      "configurations": {"nodes": [{"operator": "AND", "children": [{"operator": "OR", "cpe_match": [
+        {"vulnerable": true, "cpe23Uri": "cpe:2.3:a:wordpress:wordpress:*:*:*:*:*:*:*:*", "versionStartIncluding": "6.0", "versionEndExcluding": "6.4.3"},
+        {"vulnerable": false, "cpe23Uri": "cpe:2.3:o:linux:linux_kernel:*:*:*:*:*:*:*:*"}
+      ]}]}]},
+      "impact": {"baseMetricV3": {"cvssV3": {"baseScore": 9.8, "baseSeverity": "CRITICAL"}}}
+    },
+    {
+      "cve": {"CVE_data_meta": {"ID": "CVE-2023-0002"}},
+      "configurations": {"nodes": [{"operator": "OR", "cpe_match": [
+        {"vulnerable": true, "cpe23Uri": "cpe:2.3:a:wordpress:wordpress:6.4.2:*:*:*:*:*:*:*"}
+      ]}]},
+      "impact": {"baseMetricV2": {"cvssV2": {"baseScore": 5.0}, "severity": "MEDIUM"}}
+    },
+    {
+      "cve": {"CVE_data_meta": {"ID": "CVE-2023-0003"}},
+      "configurations": {"nodes": [{"operator": "OR", "cpe_match": [
+        {"vulnerable": true, "cpe23Uri": "cpe:2.3:a:unrelated:product:1.0:*:*:*:*:*:*:*"}
+      ]}]},
+      "impact": {}
+    }
+  ]
+}`
+
+// API 2.0 feed.
+const feed20 = `{
+  "format": "NVD_CVE",
+  "vulnerabilities": [
+    {"cve": {
+      "id": "CVE-2024-0001",
+      "metrics": {"cvssMetricV31": [
+        {"type": "Secondary", "cvssData": {"baseScore": 5.5, "baseSeverity": "MEDIUM"}},
+        {"type": "Primary", "cvssData": {"baseScore": 7.5, "baseSeverity": "HIGH"}}
+      ]},
+      "configurations": [{"nodes": [{"operator": "OR", "negate": false, "cpeMatch": [
+        {"vulnerable": true, "criteria": "cpe:2.3:a:php:php:*:*:*:*:*:*:*:*", "versionStartIncluding": "7.4.0", "versionEndIncluding": "7.4.32"}
+      ]}]}]
+    }}
+  ]
+}`
+
+func writeFeeds(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write([]byte(feed11))
+	zw.Close()
+	if err := os.WriteFile(filepath.Join(dir, "nvdcve-1.1-2023.json.gz"), gz.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "nvdcve-2.0-2024.json"), []byte(feed20), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+var wappalyzerCPEs = []string{
+	"cpe:2.3:a:wordpress:wordpress:*:*:*:*:*:*:*:*",
+	"cpe:2.3:a:php:php:*:*:*:*:*:*:*:*",
+}
+
+func TestLoadAndLookup(t *testing.T) {
+	db, err := Load(writeFeeds(t), wappalyzerCPEs)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if db.CVEs() != 3 || db.Products() != 2 {
+		t.Errorf("Load() indexed %d CVEs for %d products, want 3 for 2 (unrelated products are skipped)", db.CVEs(), db.Products())
+	}
+
+	wp := wappalyzerCPEs[0]
+	tests := []struct {
+		cpe, version string
+		want         []string
+	}{
+		{wp, "6.4.2", []string{"CVE-2023-0001", "CVE-2023-0002"}},
+		{wp, "6.0", []string{"CVE-2023-0001"}},
+		{wp, "6.4.3", nil},
+		{wp, "5.9.9", nil},
+		{wp, "", nil},
+		{wappalyzerCPEs[1], "7.4.32", []string{"CVE-2024-0001"}},
+		{wappalyzerCPEs[1], "8.1.0", nil},
+		{"cpe:2.3:a:unrelated:product:*:*:*:*:*:*:*:*", "1.0", nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, v := range db.Lookup(tt.cpe, tt.version) {
+			got = append(got, v.ID)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("Lookup(%s, %q) = %v, want %v", tt.cpe, tt.version, got, tt.want)
+		}
+	}
+
+	vulns := db.Lookup(wp, "6.1")
+	if len(vulns) != 1 || vulns[0].CVSS != 9.8 || vulns[0].Severity != "CRITICAL" || vulns[0].Versions != ">= 6.0, < 6.4.3" {
+		t.Errorf("Lookup() = %+v", vulns)
+	}
+	if vulns := db.Lookup(wappalyzerCPEs[1], "7.4.3"); len(vulns) != 1 || vulns[0].CVSS != 7.5 {
+		t.Errorf("Lookup() should use the primary CVSS metric, got %+v", vulns)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	if _, err := Load(t.TempDir(), nil); err == nil {
+		t.Error("Load() of an empty directory should fail")
+	}
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "broken.json"), []byte(`{"CVE_Items": [{`), 0644)
+	if _, err := Load(dir, nil); err == nil {
+		t.Error("Load() of a truncated feed should fail")
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"1.2", "1.2.0", 0},
+		{"1.10", "1.9", 1},
+		{"7.4.3", "7.4.32", -1},
+		{"2.0.0-rc1", "2.0.0", -1},
+		{"1.0.0a", "1.0.0b", -1},
+	}
+	for _, tt := range tests {
+		if got := compareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestEnricher(t *testing.T) {
+	db, err := Load(writeFeeds(t), wappalyzerCPEs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cpes := map[string]string{"WordPress": wappalyzerCPEs[0], "PHP": wappalyzerCPEs[1]}
+	enricher := NewEnricher(db, func(tech string) string { return cpes[tech] })
+	enricher.top = 1
+
+	detections := []model.Detection{
+		{URL: "https://a.com", Technology: "WordPress", Version: "6.4.2"},
+		{URL: "https://a.com", Technology: "PHP", Version: "7.4.3"},
+		{URL: "https://a.com", Technology: "Nginx", Version: "1.25.3"},
+	}
+	enricher.Enrich(detections)
+	if detections[0].CPE != wappalyzerCPEs[0] || len(detections[0].Vulnerabilities) != 2 {
+		t.Errorf("WordPress = %+v", detections[0])
+	}
+	if detections[2].CPE != "" || detections[2].Vulnerabilities != nil {
+		t.Errorf("Nginx without a CPE should not be enriched: %+v", detections[2])
+	}
+
+	enricher.Record(detections)
+	if enricher.Affected() != 2 {
+		t.Errorf("Affected() = %d, want 2", enricher.Affected())
+	}
+	findings := enricher.Findings()
+	if len(findings) != 1 || findings[0].Vulnerability.ID != "CVE-2023-0001" || findings[0].Others != 1 {
+		t.Errorf("Findings() = %+v, want only the CVSS 9.8 finding", findings)
+	}
+
+	var out bytes.Buffer
+	enricher.WriteSummary(&out, util.NewColorizer(false))
+	for _, want := range []string{"2 vulnerable technologies (CRITICAL 1, HIGH 1, MEDIUM 1)", "9.8", "CVE-2023-0001 (+1)", "WordPress 6.4.2"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("WriteSummary() missing %q:\n%s", want, out.String())
+		}
+	}
+}
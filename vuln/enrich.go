@@ -0,0 +1,148 @@
+package vuln
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Abhaythakor/hyperwapp/model"
+	"github.com/Abhaythakor/hyperwapp/util"
+)
+
+// DefaultTopFindings is the number of findings kept for the end-of-scan table.
+const DefaultTopFindings = 15
+
+// Finding is the most severe CVE of one detection.
+type Finding struct {
+	Target        string
+	Technology    string
+	Version       string
+	Vulnerability model.Vulnerability
+	Others        int // Further CVEs on the same detection
+}
+
+// Enricher maps detections to CPEs and, with a DB, attaches matching CVEs.
+// It is not safe for concurrent use.
+type Enricher struct {
+	db       *DB
+	cpe      func(tech string) string
+	top      int
+	findings []Finding // Sorted by descending CVSS, at most top entries
+	severity map[string]int
+	affected int
+}
+
+// NewEnricher creates an Enricher. cpe resolves a technology name to its CPE
+// (from the Wappalyzer fingerprints); db may be nil for CPE mapping only.
+func NewEnricher(db *DB, cpe func(tech string) string) *Enricher {
+	return &Enricher{db: db, cpe: cpe, top: DefaultTopFindings, severity: make(map[string]int)}
+}
+
+// Enrich fills the CPE and vulnerabilities of each detection in place.
+func (e *Enricher) Enrich(detections []model.Detection) {
+	for i := range detections {
+		d := &detections[i]
+		if d.CPE == "" && e.cpe != nil {
+			d.CPE = e.cpe(d.Technology)
+		}
+		if e.db == nil || d.CPE == "" {
+			continue
+		}
+		d.Vulnerabilities = e.db.Lookup(d.CPE, d.Version)
+	}
+}
+
+// Record adds the vulnerable detections of a batch to the end-of-scan summary.
+// It is called with the detections that are actually reported, after filters.
+func (e *Enricher) Record(detections []model.Detection) {
+	for _, d := range detections {
+		if len(d.Vulnerabilities) > 0 {
+			e.record(d)
+		}
+	}
+}
+
+func (e *Enricher) record(d model.Detection) {
+	e.affected++
+	for _, v := range d.Vulnerabilities {
+		e.severity[severityOf(v)]++
+	}
+
+	target := d.URL
+	if target == "" {
+		target = d.Domain
+	}
+	f := Finding{Target: target, Technology: d.Technology, Version: d.Version, Vulnerability: d.Vulnerabilities[0], Others: len(d.Vulnerabilities) - 1}
+	if len(e.findings) == e.top && f.Vulnerability.CVSS <= e.findings[len(e.findings)-1].Vulnerability.CVSS {
+		return
+	}
+	pos := sort.Search(len(e.findings), func(i int) bool {
+		return e.findings[i].Vulnerability.CVSS < f.Vulnerability.CVSS
+	})
+	e.findings = append(e.findings, Finding{})
+	copy(e.findings[pos+1:], e.findings[pos:])
+	e.findings[pos] = f
+	if len(e.findings) > e.top {
+		e.findings = e.findings[:e.top]
+	}
+}
+
+// Findings returns the most critical findings, highest CVSS first.
+func (e *Enricher) Findings() []Finding {
+	return e.findings
+}
+
+// Affected returns the number of detections with at least one CVE.
+func (e *Enricher) Affected() int {
+	return e.affected
+}
+
+// severityOf returns the severity of a CVE, deriving it from the score when
+// the feed has none.
+func severityOf(v model.Vulnerability) string {
+	if v.Severity != "" {
+		return strings.ToUpper(v.Severity)
+	}
+	switch {
+	case v.CVSS >= 9:
+		return "CRITICAL"
+	case v.CVSS >= 7:
+		return "HIGH"
+	case v.CVSS >= 4:
+		return "MEDIUM"
+	case v.CVSS > 0:
+		return "LOW"
+	}
+	return "UNKNOWN"
+}
+
+// WriteSummary prints the severity counts and the table of the most critical
+// findings. Nothing is written when no CVE matched.
+func (e *Enricher) WriteSummary(w io.Writer, color *util.Colorizer) {
+	if e.affected == 0 {
+		return
+	}
+
+	var counts []string
+	for _, severity := range []string{"CRITICAL", "HIGH", "MEDIUM", "LOW", "UNKNOWN"} {
+		if n := e.severity[severity]; n > 0 {
+			counts = append(counts, fmt.Sprintf("%s %d", severity, n))
+		}
+	}
+	fmt.Fprintf(w, "\n[+] %s: %d vulnerable technologies (%s)\n", color.Cyan("Vulnerabilities"), e.affected, strings.Join(counts, ", "))
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "    CVSS\tCVE\tTECHNOLOGY\tAFFECTED\tTARGET")
+	for _, f := range e.findings {
+		id := f.Vulnerability.ID
+		if f.Others > 0 {
+			id = fmt.Sprintf("%s (+%d)", id, f.Others)
+		}
+		tech := f.Technology + " " + f.Version
+		fmt.Fprintf(tw, "    %.1f\t%s\t%s\t%s\t%s\n", f.Vulnerability.CVSS, id, tech, f.Vulnerability.Versions, f.Target)
+	}
+	tw.Flush()
+	fmt.Fprintln(w)
+}